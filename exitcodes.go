@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+
+	"rgehrsitz/ferex_cli/pkg/calc"
+	"rgehrsitz/ferex_cli/pkg/config"
+)
+
+// Exit codes returned by ferex so scripts and CI can tell failures apart
+const (
+	exitOK          = 0 // Success
+	exitError       = 1 // Usage errors and any other failure
+	exitConfigLoad  = 2 // Config or bundle file could not be read or parsed
+	exitValidation  = 3 // Config failed validation
+	exitCalculation = 4 // Calculation could not be completed
+)
+
+// codedError pairs an error with the process exit code it should produce
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// withExitCode tags err with an exit code
+func withExitCode(code int, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// exitCode returns the exit code for an error returned by a command
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	switch {
+	case errors.Is(err, config.ErrLoad):
+		return exitConfigLoad
+	case errors.Is(err, config.ErrValidation):
+		return exitValidation
+	case errors.Is(err, calc.ErrIneligible):
+		return exitCalculation
+	}
+
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return exitError
+}
@@ -6,6 +6,7 @@ import (
 
 // Config represents the complete retirement planning configuration
 type Config struct {
+	Version        int                `yaml:"version,omitempty"` // Schema version; older files are migrated on load
 	Personal       PersonalInfo       `yaml:"personal" validate:"required"`
 	Employment     EmploymentInfo     `yaml:"employment" validate:"required"`
 	Retirement     RetirementInfo     `yaml:"retirement" validate:"required"`
@@ -13,6 +14,13 @@ type Config struct {
 	SocialSecurity SocialSecurityInfo `yaml:"social_security"`
 	HealthInsurance HealthInsuranceInfo `yaml:"health_insurance,omitempty"`
 	TaxInfo        TaxInfo            `yaml:"tax_info,omitempty"`
+	Assumptions    AssumptionsInfo    `yaml:"assumptions,omitempty"`
+	Spending       SpendingInfo       `yaml:"spending,omitempty"`
+	CashReserve    CashReserveInfo    `yaml:"cash_reserve,omitempty"`
+	HSA            HSAInfo            `yaml:"hsa,omitempty"`
+	OtherPensions  []OtherPension     `yaml:"other_pensions,omitempty" validate:"omitempty,dive"`
+	RothConversions []RothConversion  `yaml:"roth_conversions,omitempty" validate:"omitempty,dive"`
+	Confidence     ConfidenceInfo     `yaml:"confidence,omitempty"`
 	Output         OutputOptions      `yaml:"output,omitempty"`
 }
 
@@ -21,12 +29,33 @@ type PersonalInfo struct {
 	Name           string    `yaml:"name" validate:"required"`
 	BirthDate      time.Time `yaml:"birth_date" validate:"required"`
 	RetirementSystem string  `yaml:"retirement_system" validate:"required,oneof=FERS CSRS"`
+	// Optional: used to check that a survivor benefit election has an eligible beneficiary
+	MaritalStatus  string    `yaml:"marital_status,omitempty" validate:"omitempty,oneof=single married divorced widowed"`
+	// Optional: age at which to model the retiree's death; later years project the survivor's income
+	AssumedDeathAge int      `yaml:"assumed_death_age,omitempty" validate:"omitempty,min=50,max=110"`
+	// Optional: used to time the survivor's Social Security claim; defaults to the retiree's birth date
+	SpouseBirthDate time.Time `yaml:"spouse_birth_date,omitempty"`
+	// Optional: law enforcement officer, firefighter, or air traffic controller covered by the
+	// special provisions, who may retire at 50 with 20 years or at any age with 25
+	SpecialProvision bool    `yaml:"special_provision,omitempty"`
 }
 
 // EmploymentInfo contains federal employment details
 type EmploymentInfo struct {
 	HireDate        time.Time `yaml:"hire_date" validate:"required"`
 	High3Salary     float64   `yaml:"high_3_salary" validate:"required,gt=0"`
+	// Optional: date the high-3 was measured; with ProjectHigh3 set, the high-3 grows at
+	// SalaryGrowthRate (default: inflation) from then to the retirement date. By default it is held flat.
+	High3AsOf        time.Time `yaml:"high_3_as_of,omitempty"`
+	ProjectHigh3     bool      `yaml:"project_high_3,omitempty"`
+	SalaryGrowthRate float64   `yaml:"salary_growth_rate,omitempty" validate:"omitempty,gte=0,lte=0.10"`
+	// Optional: final annual salary, used for the gross_final_salary replacement ratio
+	FinalSalary      float64   `yaml:"final_salary,omitempty" validate:"omitempty,gt=0"`
+	// Optional: share of salary contributed to the Traditional TSP while working
+	TSPContributionRate float64 `yaml:"tsp_contribution_rate,omitempty" validate:"omitempty,gte=0,lte=1"`
+	// Optional: annual leave paid out as a taxable lump sum at retirement; unlike sick leave
+	// it does not count toward the annuity
+	UnusedAnnualLeaveHours float64 `yaml:"unused_annual_leave_hours,omitempty" validate:"omitempty,gte=0"`
 	CreditableService CreditableService `yaml:"creditable_service" validate:"required"`
 }
 
@@ -34,9 +63,37 @@ type EmploymentInfo struct {
 // total_years is calculated automatically from hire_date, target_retirement_date, and other periods.
 type CreditableService struct {
 	TotalYears      float64           `yaml:"total_years,omitempty" validate:"omitempty,gt=0"` // Derived, do not supply in YAML
+	SuppliedTotalYears float64        `yaml:"-" json:"-"` // total_years as written in the file, kept for reconciliation
 	PartTimePeriods []PartTimePeriod  `yaml:"part_time_periods,omitempty"`
 	MilitaryService *MilitaryService  `yaml:"military_service,omitempty"`
 	UnusedSickLeave float64           `yaml:"unused_sick_leave,omitempty" validate:"omitempty,gte=0"`
+	Transfer        *TransferService  `yaml:"transfer,omitempty"`
+	NonDeductionPeriods []NonDeductionPeriod `yaml:"non_deduction_periods,omitempty" validate:"omitempty,dive"`
+	RefundedService []RefundedPeriod  `yaml:"refunded_service,omitempty" validate:"omitempty,dive"`
+}
+
+// RefundedPeriod is prior federal service whose retirement contributions were refunded
+// A paid redeposit restores the period's service credit; without one the period is not credited.
+type RefundedPeriod struct {
+	StartDate     time.Time `yaml:"start_date" validate:"required"`
+	EndDate       time.Time `yaml:"end_date" validate:"required"`
+	RedepositPaid bool      `yaml:"redeposit_paid,omitempty"`
+}
+
+// NonDeductionPeriod is CSRS service during which no retirement deductions were withheld
+// Unpaid deposits for service before October 1, 1982 reduce the annuity by 10% of the deposit owed.
+type NonDeductionPeriod struct {
+	StartDate   time.Time `yaml:"start_date" validate:"required"`
+	EndDate     time.Time `yaml:"end_date" validate:"required"`
+	DepositOwed float64   `yaml:"deposit_owed" validate:"gte=0"`
+	DepositPaid bool      `yaml:"deposit_paid,omitempty"`
+}
+
+// TransferService splits the service of a CSRS-to-FERS transferee into its two components
+// FERSYears defaults to the total service less CSRSYears.
+type TransferService struct {
+	CSRSYears float64 `yaml:"csrs_years" validate:"required,gt=0"`
+	FERSYears float64 `yaml:"fers_years,omitempty" validate:"omitempty,gt=0"`
 }
 
 // PartTimePeriod represents a period of part-time employment
@@ -57,6 +114,35 @@ type RetirementInfo struct {
 	TargetRetirementDate time.Time `yaml:"target_retirement_date" validate:"required"`
 	SurvivorBenefit string `yaml:"survivor_benefit" validate:"required,oneof=full partial none"`
 	EarlyRetirement *EarlyRetirementInfo `yaml:"early_retirement,omitempty"`
+	// Optional: age a deferred annuity begins after separating at the target retirement date
+	DeferredStartAge int `yaml:"deferred_start_age,omitempty" validate:"omitempty,min=55,max=70"`
+	PhasedRetirement *PhasedRetirementInfo `yaml:"phased_retirement,omitempty"`
+	// Optional: exact monthly FERS supplement from an agency estimate, replacing the simplified formula
+	SupplementOverride float64 `yaml:"supplement_override,omitempty" validate:"omitempty,gt=0"`
+	// Optional: monthly age-62 Social Security estimate counting only FERS-covered earnings,
+	// used as the supplement's base in place of the PIA
+	SupplementSSBase float64 `yaml:"supplement_ss_base,omitempty" validate:"omitempty,gt=0"`
+	// Optional: annual wages from work after retiring, counted only by the FERS supplement's
+	// earnings test; the wages themselves are not projected as income
+	SupplementEarnings float64 `yaml:"supplement_earnings,omitempty" validate:"omitempty,gte=0"`
+	// Optional: leave the FERS supplement out entirely for a conservative projection
+	DisableSupplement bool `yaml:"disable_supplement,omitempty"`
+	// Optional: leave out the CSRS refund of contributions for service beyond the 80% cap
+	DisableExcessRefund bool `yaml:"disable_excess_refund,omitempty"`
+	// Optional: TSP balance to leave at the projection end, in first-year-of-retirement dollars;
+	// used by the bequest withdrawal strategy
+	BequestTarget float64 `yaml:"bequest_target,omitempty" validate:"omitempty,gte=0"`
+	// Optional: "net_high3" (default) divides first-year net income by the high-3;
+	// "gross_final_salary" divides first-year gross income by the final salary
+	ReplacementRatioBasis string `yaml:"replacement_ratio_basis,omitempty" validate:"omitempty,oneof=net_high3 gross_final_salary"`
+}
+
+// PhasedRetirementInfo models OPM phased retirement starting at the target retirement date
+// The retiree works a half-time schedule drawing 50% of the annuity for Years, then fully
+// retires with a composite annuity. This section is optional.
+type PhasedRetirementInfo struct {
+	Years          int     `yaml:"years" validate:"required,gt=0"`
+	PartTimeSalary float64 `yaml:"part_time_salary,omitempty" validate:"omitempty,gte=0"` // Defaults to 50% of high-3
 }
 
 // EarlyRetirementInfo contains early retirement options
@@ -71,19 +157,65 @@ type EarlyRetirementInfo struct {
 type TSPInfo struct {
 	TraditionalBalance  float64 `yaml:"traditional_balance" validate:"required,gte=0"`
 	RothBalance         float64 `yaml:"roth_balance" validate:"required,gte=0"`
-	WithdrawalStrategy  string  `yaml:"withdrawal_strategy" validate:"required,oneof=fixed_amount life_expectancy lump_sum percentage"`
+	WithdrawalStrategy  string  `yaml:"withdrawal_strategy" validate:"required,oneof=fixed_amount life_expectancy lump_sum percentage bequest income_only custom_schedule"`
 	WithdrawalAmount    float64 `yaml:"withdrawal_amount" validate:"gte=0"` // Used if strategy is fixed_amount
+	// Optional: yearly increase to a fixed_amount withdrawal after the first year of retirement
+	WithdrawalCOLA      float64 `yaml:"withdrawal_cola,omitempty" validate:"omitempty,gte=0,lte=0.10"`
 	WithdrawalRate      float64 `yaml:"withdrawal_rate" validate:"gte=0,lte=0.20"` // Used if strategy is percentage
-	GrowthRate          float64 `yaml:"growth_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	// Optional: "gross" (default) withdraws withdrawal_amount as is; "net" treats it as the desired
+	// amount after taxes and grosses the withdrawal up to cover the tax it adds each year
+	WithdrawalBasis     string  `yaml:"withdrawal_basis,omitempty" validate:"omitempty,oneof=gross net"`
+	// Used if strategy is custom_schedule: annual withdrawal by age; ages not listed withdraw nothing
+	WithdrawalSchedule  map[int]float64 `yaml:"withdrawal_schedule,omitempty" validate:"omitempty,dive,gte=0"`
+	// Optional: unset takes the assumption profile's rate; an explicit 0 means no growth
+	GrowthRate          *float64 `yaml:"growth_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	// Optional: most growth credited in any single year, whatever the assumed or sequenced return
+	MaxAnnualGrowth     float64 `yaml:"max_annual_growth,omitempty" validate:"omitempty,gt=0,lte=1"`
+	// Optional guardrails applied after the strategy amount; setting either adds an RMD floor
+	WithdrawalFloor     float64 `yaml:"withdrawal_floor,omitempty" validate:"omitempty,gte=0"`
+	WithdrawalCeiling   float64 `yaml:"withdrawal_ceiling,omitempty" validate:"omitempty,gte=0"`
+	// Optional: "traditional" (default) draws and taxes Traditional money until it runs out, then
+	// Roth; "proportional" draws pro-rata from
+	// Traditional and Roth and taxes only the Traditional share; "bracket_fill" draws Traditional
+	// up to the top of the 12% federal bracket, then Roth
+	WithdrawalSource    string  `yaml:"withdrawal_source,omitempty" validate:"omitempty,oneof=traditional proportional bracket_fill"`
+	// Optional: shortcut treating the whole TSP as "all_traditional" or "all_roth" for taxes in
+	// place of the entered balance split; "split" (default) uses the balances as entered
+	TaxTreatment        string  `yaml:"tax_treatment,omitempty" validate:"omitempty,oneof=all_traditional all_roth split"`
+	// Optional: federal bracket rate bracket_fill fills with Traditional money (default 0.12)
+	BracketFillRate     float64 `yaml:"bracket_fill_rate,omitempty" validate:"omitempty,gt=0,lt=1"`
+	// Optional: actual annual returns for the first years of retirement, e.g. to stress a
+	// bad sequence; later years use GrowthRate
+	ReturnSequence      []float64 `yaml:"return_sequence,omitempty" validate:"omitempty,dive,gte=-0.9,lte=1"`
+	// Optional: when withdrawals come out relative to the year's growth: "end_of_year" (default)
+	// grows the full starting balance, "begin_of_year" grows what remains after withdrawing,
+	// and "mid_year" grows the balance net of half the withdrawal
+	GrowthTiming        string  `yaml:"growth_timing,omitempty" validate:"omitempty,oneof=begin_of_year mid_year end_of_year"`
+	// Optional: total balance above which a likely-typo warning is shown (default $3,000,000)
+	PlausibleBalanceLimit float64 `yaml:"plausible_balance_limit,omitempty" validate:"omitempty,gt=0"`
 }
 
 // SocialSecurityInfo contains Social Security benefit information
 type SocialSecurityInfo struct {
 	EstimatedPIA float64 `yaml:"estimated_pia" validate:"required,gt=0"`
 	ClaimingAge  int     `yaml:"claiming_age" validate:"required,min=62,max=70"`
+	// Optional: survivor's age when claiming widow(er) benefits on the retiree's record after
+	// the retiree's death; survivor benefits may start at 60, unlike the retiree's own at 62
+	SurvivorSSClaimingAge int `yaml:"survivor_claiming_age,omitempty" validate:"omitempty,min=60,max=70"`
+	// Optional: "what if Congress does nothing" - cut all benefits by TrustFundCut (e.g. 0.23)
+	// from TrustFundCutYear onward
+	TrustFundCutYear int     `yaml:"trust_fund_cut_year,omitempty" validate:"omitempty,min=2025,max=2100"`
+	TrustFundCut     float64 `yaml:"trust_fund_cut,omitempty" validate:"omitempty,gt=0,lte=1"`
 	SpouseBenefit *SpouseBenefit `yaml:"spouse_benefit,omitempty"`
 	// Optional: Monthly estimates from SS statement at different ages
 	MonthlyEstimates map[int]float64 `yaml:"monthly_estimates,omitempty"`
+	// Optional: statutory maximum PIA used to cap benefits (defaults to the 2025 maximum)
+	MaxPIA float64 `yaml:"max_pia,omitempty" validate:"omitempty,gt=0"`
+	// Optional: covered earnings by year; when set, EstimatedPIA is derived from the highest
+	// ComputationYears (default 35) indexed years with the AIME bend-point formula
+	EarningsHistory  map[int]float64 `yaml:"earnings_history,omitempty" validate:"omitempty,dive,gte=0"`
+	ComputationYears int             `yaml:"computation_years,omitempty" validate:"omitempty,min=1,max=40"`
+	SuppliedPIA      float64         `yaml:"-" json:"-"` // estimated_pia as written in the file, kept to flag a conflict with the earnings history
 }
 
 // SpouseBenefit represents spouse Social Security information
@@ -93,26 +225,153 @@ type SpouseBenefit struct {
 }
 
 // HealthInsuranceInfo contains health insurance premium information
+// CurrentPremium is the active enrollee share and carries into retirement when RetirementPremium is unset.
 type HealthInsuranceInfo struct {
 	CurrentPremium    float64 `yaml:"current_premium,omitempty" validate:"omitempty,gte=0"`
 	RetirementPremium float64 `yaml:"retirement_premium,omitempty" validate:"omitempty,gte=0"`
 	PremiumCOLA       float64 `yaml:"premium_cola,omitempty" validate:"omitempty,gte=0,lte=0.10"`
 	Plan              string  `yaml:"plan,omitempty"`
+	// Optional: the surviving spouse's own annual FEHB premium after the retiree's death,
+	// deducted from the survivor annuity; defaults to the retirement premium
+	SurvivorPremium   float64 `yaml:"survivor_premium,omitempty" validate:"omitempty,gte=0"`
+}
+
+// SpendingInfo contains required retirement spending used to detect income shortfalls
+// AnnualAmount is in first-year-of-retirement dollars and grows with inflation;
+// one-time expenses are entered in the dollars of the year they occur.
+type SpendingInfo struct {
+	AnnualAmount    float64          `yaml:"annual_amount,omitempty" validate:"omitempty,gte=0"`
+	OneTimeExpenses []OneTimeExpense `yaml:"one_time_expenses,omitempty" validate:"omitempty,dive"`
+}
+
+// OneTimeExpense is a lump expense in a single year, such as a new roof or car
+type OneTimeExpense struct {
+	Age         int     `yaml:"age" validate:"required,gt=0"`
+	Amount      float64 `yaml:"amount" validate:"required,gt=0"`
+	Description string  `yaml:"description,omitempty"`
+}
+
+// OtherPension is a pension from non-federal employment, such as a prior private or state employer
+// AnnualAmount is the first-year benefit at StartAge; COLA compounds from then on.
+type OtherPension struct {
+	Name         string  `yaml:"name,omitempty"`
+	AnnualAmount float64 `yaml:"annual_amount" validate:"required,gt=0"`
+	StartAge     int     `yaml:"start_age" validate:"required,min=40,max=100"`
+	COLA         float64 `yaml:"cola,omitempty" validate:"omitempty,gte=0,lte=0.10"`
+	TaxExempt    bool    `yaml:"tax_exempt,omitempty"`
+}
+
+// RothConversion is a planned conversion of pre-tax money to Roth in one year; the amount
+// converted is taxable that year. "tsp" is an in-plan conversion that moves Traditional TSP
+// money to Roth TSP; "ira" converts an IRA that is not otherwise modeled, so only the tax
+// is projected.
+type RothConversion struct {
+	Age    int     `yaml:"age" validate:"required,min=40,max=100"`
+	Amount float64 `yaml:"amount" validate:"required,gt=0"`
+	Source string  `yaml:"source" validate:"required,oneof=tsp ira"`
+}
+
+// CashReserveInfo models a cash bucket spent instead of the TSP in years the TSP loses value
+// The bucket is refilled from the TSP up to Balance in years with a positive return.
+type CashReserveInfo struct {
+	Balance      float64 `yaml:"balance,omitempty" validate:"omitempty,gte=0"`
+	InterestRate float64 `yaml:"interest_rate,omitempty" validate:"omitempty,gte=0,lte=0.10"`
+}
+
+// HSAInfo models a Health Savings Account spent tax-free on health insurance premiums
+// Premiums are qualified medical expenses from age 65, so withdrawals start then and
+// pay the year's premium, up to MaxAnnualWithdrawal when set, until the balance runs out.
+type HSAInfo struct {
+	Balance             float64 `yaml:"balance,omitempty" validate:"omitempty,gte=0"`
+	GrowthRate          float64 `yaml:"growth_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	MaxAnnualWithdrawal float64 `yaml:"max_annual_withdrawal,omitempty" validate:"omitempty,gt=0"`
+}
+
+// ConfidenceInfo records how certain key inputs are: "known" or "estimate"
+// Inputs marked as estimates are listed in the summary's data quality notes.
+type ConfidenceInfo struct {
+	High3Salary     string `yaml:"high_3_salary,omitempty" validate:"omitempty,oneof=known estimate"`
+	EstimatedPIA    string `yaml:"estimated_pia,omitempty" validate:"omitempty,oneof=known estimate"`
+	TSPBalance      string `yaml:"tsp_balance,omitempty" validate:"omitempty,oneof=known estimate"`
+	HealthPremium   string `yaml:"health_premium,omitempty" validate:"omitempty,oneof=known estimate"`
 }
 
 // TaxInfo contains state and tax-related information
 type TaxInfo struct {
-	State              string  `yaml:"state,omitempty"`
-	StateTaxRate       float64 `yaml:"state_tax_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
-	PensionTaxExempt   bool    `yaml:"pension_tax_exempt,omitempty"`
-	SSTaxExempt        bool    `yaml:"ss_tax_exempt,omitempty"`
-	FilingStatus       string  `yaml:"filing_status,omitempty" validate:"omitempty,oneof=single mfj mfs hoh"`
+	State            string            `yaml:"state,omitempty"`
+	StateTaxRate     float64           `yaml:"state_tax_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	PensionTaxExempt bool              `yaml:"pension_tax_exempt,omitempty"`
+	SSTaxExempt      bool              `yaml:"ss_tax_exempt,omitempty"`
+	FilingStatus     string            `yaml:"filing_status,omitempty" validate:"omitempty,oneof=single mfj mfs hoh"`
+	// Optional: grow the federal brackets and standard deduction with inflation each year after
+	// 2025; the Social Security taxation thresholds are fixed in law and stay constant
+	IndexBrackets    bool              `yaml:"index_brackets,omitempty"`
+	ResidenceChanges []ResidenceChange `yaml:"residence_changes,omitempty" validate:"omitempty,dive"`
+	CustomStateTax   *CustomStateTax   `yaml:"custom_state_tax,omitempty"`
+}
+
+// CustomStateTax describes a state income tax not built into the calculator
+// When set it replaces the built-in rules and flat rate for the state. Brackets must be in
+// ascending order of Over; the Age65Exclusion is subtracted from taxable income from age 65.
+type CustomStateTax struct {
+	Brackets       []StateTaxBracket `yaml:"brackets" validate:"required,min=1,dive"`
+	PensionExempt  bool              `yaml:"pension_exempt,omitempty"`
+	SSExempt       bool              `yaml:"ss_exempt,omitempty"`
+	TSPExempt      bool              `yaml:"tsp_exempt,omitempty"`
+	Age65Exclusion float64           `yaml:"age_65_exclusion,omitempty" validate:"omitempty,gte=0"`
+}
+
+// StateTaxBracket taxes income above Over at Rate, up to the next bracket
+type StateTaxBracket struct {
+	Over float64 `yaml:"over" validate:"gte=0"`
+	Rate float64 `yaml:"rate" validate:"gte=0,lte=0.15"`
+}
+
+// ResidenceChange moves the retiree to a new state from the given age onward
+// StateTaxRate and the exemption flags override the built-in rules for that state, as in TaxInfo.
+// Month places the move within the year at Age, apportioning that year's state tax between
+// the two states; without it the new state taxes the whole year.
+type ResidenceChange struct {
+	Age              int     `yaml:"age" validate:"required,gt=0"`
+	Month            int     `yaml:"month,omitempty" validate:"omitempty,min=1,max=12"`
+	State            string  `yaml:"state" validate:"required"`
+	StateTaxRate     float64 `yaml:"state_tax_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	PensionTaxExempt bool    `yaml:"pension_tax_exempt,omitempty"`
+	SSTaxExempt      bool    `yaml:"ss_tax_exempt,omitempty"`
+	CustomStateTax   *CustomStateTax `yaml:"custom_state_tax,omitempty"`
+}
+
+// AssumptionsInfo contains the economic assumptions used for projections
+// The named profile supplies TSP growth, inflation, and COLA rates together;
+// any rate set explicitly (including tsp.growth_rate) takes precedence over the profile.
+type AssumptionsInfo struct {
+	Profile       string  `yaml:"profile,omitempty" validate:"omitempty,oneof=optimistic base pessimistic"`
+	// Optional: unset rates take the profile's; an explicit 0 is kept
+	InflationRate *float64 `yaml:"inflation_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	COLARate      *float64 `yaml:"cola_rate,omitempty" validate:"omitempty,gte=0,lte=0.15"`
+	// Optional: also report lifetime income weighted by the probability of being alive each year
+	MortalityWeighted bool `yaml:"mortality_weighted,omitempty"`
+	// Optional: also report lifetime income discounted to its present value at this annual rate
+	DiscountRate  float64 `yaml:"discount_rate,omitempty" validate:"omitempty,gt=0,lte=0.15"`
+	// Optional: evaluate the plan as of this date instead of today, for reproducible results
+	AsOf          time.Time `yaml:"as_of,omitempty"`
 }
 
 // OutputOptions controls output formatting
 type OutputOptions struct {
-	Format     string `yaml:"format" validate:"omitempty,oneof=json csv yaml table"`
+	Format     string `yaml:"format" validate:"omitempty,oneof=json ndjson csv yaml table"`
 	Verbose    bool   `yaml:"verbose,omitempty"`
 	OutputFile string `yaml:"output_file,omitempty"`
 	Monthly    bool   `yaml:"monthly,omitempty"`
-}
\ No newline at end of file
+	// Optional: show each income source in monthly and annual columns; Monthly picks the primary column
+	SideBySide bool   `yaml:"side_by_side,omitempty"`
+	// Optional: list the age each income source starts and stops in table output
+	Timeline   bool   `yaml:"timeline,omitempty"`
+	// Optional: Go time layout for dates in table output; JSON always uses RFC 3339
+	DateFormat string `yaml:"date_format,omitempty"`
+}
+
+// Rate returns a pointer to rate, for setting an optional rate such as TSPInfo.GrowthRate in code
+func Rate(rate float64) *float64 {
+	return &rate
+}
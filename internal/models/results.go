@@ -12,14 +12,26 @@ type RetirementResults struct {
 // RetirementSummary provides key summary metrics
 type RetirementSummary struct {
 	// Basic pension information
+	CreditableService    float64 `json:"creditable_service"`
 	MonthlyPension       float64 `json:"monthly_pension"`
 	AnnualPension        float64 `json:"annual_pension"`
 	PensionReductionPct  float64 `json:"pension_reduction_pct,omitempty"`
+	ExcessContributionRefund float64 `json:"excess_contribution_refund,omitempty"` // Paid at retirement
+	AnnualLeavePayout    float64 `json:"annual_leave_payout,omitempty"` // Paid at retirement
+	TSPRothConversions   float64 `json:"tsp_roth_conversions,omitempty"` // Total converted in-plan
+	IRARothConversions   float64 `json:"ira_roth_conversions,omitempty"` // Total converted from IRAs
 	
 	// Survivor benefit impact
 	SurvivorBenefitCost  float64 `json:"survivor_benefit_cost,omitempty"`
+	SurvivorAnnuity      float64 `json:"survivor_annuity,omitempty"`
+	SurvivorBreakEvenAge float64 `json:"survivor_break_even_age,omitempty"`
 	NetMonthlyPension    float64 `json:"net_monthly_pension"`
 	
+	// Phased retirement (if applicable)
+	PhasedAnnuity        float64 `json:"phased_annuity,omitempty"`
+	CompositeAnnuity     float64 `json:"composite_annuity,omitempty"`
+	PhasedEndAge         int     `json:"phased_end_age,omitempty"`
+	
 	// FERS Supplement (if applicable)
 	FERSSupplement       float64 `json:"fers_supplement,omitempty"`
 	SupplementEndAge     int     `json:"supplement_end_age,omitempty"`
@@ -28,14 +40,59 @@ type RetirementSummary struct {
 	MonthlySocialSecurity float64 `json:"monthly_social_security"`
 	SocialSecurityStartAge int    `json:"social_security_start_age"`
 	
+	// Years with neither the FERS supplement nor Social Security
+	IncomeGapStartAge    int     `json:"income_gap_start_age,omitempty"`
+	IncomeGapYears       int     `json:"income_gap_years,omitempty"`
+	IncomeGapDip         float64 `json:"income_gap_dip,omitempty"` // Drop in gross income entering the gap
+	SupplementGapYears   int     `json:"supplement_gap_years,omitempty"` // Years between the supplement ending and Social Security
+	SupplementGapLoss    float64 `json:"supplement_gap_loss,omitempty"`  // Supplement income not replaced during those years
+	
 	// TSP projections
 	TSPStartingBalance   float64 `json:"tsp_starting_balance"`
 	TSPProjectedDepletion int    `json:"tsp_projected_depletion,omitempty"`
+	SustainableWithdrawalRate float64 `json:"sustainable_withdrawal_rate"` // Inflation-adjusted rate that lasts to the projection end
+	
+	// Fixed TSP withdrawal solved to meet a first-year net income target
+	TargetNetIncome      float64 `json:"target_net_income,omitempty"`
+	SolvedTSPWithdrawal  float64 `json:"solved_tsp_withdrawal,omitempty"`
+	
+	// First year taxable income crosses into a higher federal bracket
+	FirstTaxCliffAge     int     `json:"first_tax_cliff_age,omitempty"`
+	FirstTaxCliffYear    int     `json:"first_tax_cliff_year,omitempty"`
+	TaxCliffBracketRate  float64 `json:"tax_cliff_bracket_rate,omitempty"`
+	
+	// Years net income falls short of required spending
+	ShortfallYears       int     `json:"shortfall_years,omitempty"`
+	FirstShortfallAge    int     `json:"first_shortfall_age,omitempty"`
+	TotalShortfall       float64 `json:"total_shortfall,omitempty"`
 	
 	// Overall financial picture
 	FirstYearIncome      float64 `json:"first_year_income"`
 	LifetimeIncome       float64 `json:"lifetime_income"`
+	ExpectedLifetimeIncome float64 `json:"expected_lifetime_income,omitempty"` // Mortality-weighted lifetime income
+	LifetimeIncomeNPV    float64 `json:"lifetime_income_npv,omitempty"` // Lifetime income discounted to retirement
 	ReplacementRatio     float64 `json:"replacement_ratio"`
+	ReplacementRatioBasis string `json:"replacement_ratio_basis"`
+	WorkingNetIncome     float64 `json:"working_net_income"`     // Take-home pay in the final working year
+	NetReplacementRatio  float64 `json:"net_replacement_ratio"`  // First-year net income / working net income
+	FirstYearIncomeMix   IncomeComposition `json:"first_year_income_mix"`
+	LifetimeIncomeMix    IncomeComposition `json:"lifetime_income_mix"`
+	
+	// Headline numbers that depend on rough estimates
+	DataQuality          []string `json:"data_quality,omitempty"`
+	
+	// Changes to the plan worth considering
+	Suggestions          []string `json:"suggestions,omitempty"`
+	
+	// Ages each income source starts and stops, in age order
+	Timeline             []TimelineEvent `json:"timeline,omitempty"`
+}
+
+// TimelineEvent is the first projection year an income source starts or stops
+type TimelineEvent struct {
+	Age   int    `json:"age"`
+	Year  int    `json:"year"`
+	Event string `json:"event"`
 }
 
 // AnnualProjection represents one year of retirement income and expenses
@@ -47,22 +104,58 @@ type AnnualProjection struct {
 	PensionIncome     float64 `json:"pension_income"`
 	FERSSupplementIncome float64 `json:"fers_supplement_income"`
 	SocialSecurityIncome float64 `json:"social_security_income"`
+	SpousalSSIncome   float64 `json:"spousal_ss_income,omitempty"` // Spouse's benefit on the retiree's record
 	TSPWithdrawal     float64 `json:"tsp_withdrawal"`
+	CashWithdrawal    float64 `json:"cash_withdrawal,omitempty"` // Spent from the cash reserve instead of the TSP
+	TaxableTSPWithdrawal float64 `json:"taxable_tsp_withdrawal"`
+	SalaryIncome      float64 `json:"salary_income,omitempty"`
+	ContributionRefund float64 `json:"contribution_refund,omitempty"` // CSRS excess-contribution refund, first year only; not taxed or in gross income
+	AnnualLeavePayout float64 `json:"annual_leave_payout,omitempty"` // Taxable lump sum for unused annual leave, first year only
+	OtherPensionIncome float64 `json:"other_pension_income,omitempty"` // Non-federal pensions
+	TaxableOtherPension float64 `json:"taxable_other_pension,omitempty"`
+	TSPRothConversion float64 `json:"tsp_roth_conversion,omitempty"` // Taxable, but not income to spend
+	IRARothConversion float64 `json:"ira_roth_conversion,omitempty"`
+	IncomeGap         bool    `json:"income_gap,omitempty"` // No FERS supplement while waiting for Social Security to start
+	RetireeDeceased   bool    `json:"retiree_deceased,omitempty"` // Pension income is the survivor annuity
 	OtherIncome       float64 `json:"other_income"`
 	GrossIncome       float64 `json:"gross_income"`
 	
 	// Taxes and deductions
 	FederalTax        float64 `json:"federal_tax"`
+	EffectiveTaxRate  float64 `json:"effective_tax_rate"`
+	MarginalTaxRate   float64 `json:"marginal_tax_rate"`
+	TopBracketRate    float64 `json:"top_bracket_rate"`
+	BracketCrossing   bool    `json:"bracket_crossing,omitempty"` // Top bracket is higher than the prior year's
+	MAGI              float64 `json:"magi"`
+	IRMAATier         int     `json:"irmaa_tier,omitempty"` // Medicare surcharge tier from MAGI, 0 for none
 	StateTax          float64 `json:"state_tax"`
 	HealthInsurance   float64 `json:"health_insurance"`
+	HSAWithdrawal     float64 `json:"hsa_withdrawal,omitempty"` // Tax-free, offsets the health insurance premium
 	LifeInsurance     float64 `json:"life_insurance"`
+	TSPContribution   float64 `json:"tsp_contribution,omitempty"` // Pre-tax contributions from phased-retirement salary
 	TotalDeductions   float64 `json:"total_deductions"`
 	NetIncome         float64 `json:"net_income"`
 	
+	// Spending needs
+	RequiredSpending  float64 `json:"required_spending,omitempty"`
+	OneTimeExpenses   float64 `json:"one_time_expenses,omitempty"`
+	Surplus           float64 `json:"surplus,omitempty"` // Net income less spending; negative in shortfall years
+	Shortfall         float64 `json:"shortfall,omitempty"`
+	
 	// TSP account status
 	TSPStartBalance   float64 `json:"tsp_start_balance"`
+	TSPReturn         float64 `json:"tsp_return"`
 	TSPGrowth         float64 `json:"tsp_growth"`
 	TSPEndBalance     float64 `json:"tsp_end_balance"`
+	RMD               float64 `json:"rmd,omitempty"` // Traditional TSP required minimum distribution due while the retiree is alive
+	
+	// Cash reserve status
+	CashRefill        float64 `json:"cash_refill,omitempty"` // Moved from the TSP to the cash reserve
+	TaxableCashRefill float64 `json:"taxable_cash_refill,omitempty"` // Traditional share of the refill, taxed that year
+	CashEndBalance    float64 `json:"cash_end_balance,omitempty"`
+	
+	// HSA status
+	HSAEndBalance     float64 `json:"hsa_end_balance,omitempty"`
 	
 	// COLA adjustments
 	COLARate          float64 `json:"cola_rate"`
@@ -74,12 +167,22 @@ type CalculationMetadata struct {
 	CalculationDate   time.Time `json:"calculation_date"`
 	ConfigVersion     string    `json:"config_version"`
 	CalculationEngine string    `json:"calculation_engine"`
+	Inputs            CalculationInputs `json:"inputs"`
 	Assumptions       CalculationAssumptions `json:"assumptions"`
 	Warnings          []string  `json:"warnings,omitempty"`
 }
 
+// CalculationInputs records the key inputs that produced the results
+type CalculationInputs struct {
+	Name              string  `json:"name,omitempty"`
+	RetirementSystem  string  `json:"retirement_system"`
+	RetirementAge     int     `json:"retirement_age"`
+	High3Salary       float64 `json:"high_3_salary"`
+}
+
 // CalculationAssumptions documents the assumptions used
 type CalculationAssumptions struct {
+	Profile           string  `json:"profile,omitempty"`
 	InflationRate     float64 `json:"inflation_rate"`
 	TSPGrowthRate     float64 `json:"tsp_growth_rate"`
 	LifeExpectancy    int     `json:"life_expectancy"`
@@ -92,6 +195,15 @@ type CalculationAssumptions struct {
 type ComparisonResults struct {
 	Scenarios         []RetirementResults `json:"scenarios"`
 	ComparisonMetrics ComparisonMetrics   `json:"comparison_metrics"`
+	Baseline          int                 `json:"baseline"` // Index of the scenario deltas are measured against
+	Deltas            []ScenarioDelta     `json:"deltas"`   // One per scenario, in scenario order
+}
+
+// ScenarioDelta is a scenario's signed difference from the comparison baseline
+// TSPDepletionDelta is nil unless the TSP depletes within the projection in both scenarios.
+type ScenarioDelta struct {
+	LifetimeIncome    float64 `json:"lifetime_income"`
+	TSPDepletionDelta *int    `json:"tsp_depletion_delta,omitempty"`
 }
 
 // ComparisonMetrics provides comparison statistics
@@ -102,13 +214,116 @@ type ComparisonMetrics struct {
 	ReplacementRatioSpread  float64           `json:"replacement_ratio_spread"`
 }
 
+// PensionTargetSolution reports the high-3 or additional service needed to reach a target
+// unreduced annual annuity, holding the multiplier fixed
+type PensionTargetSolution struct {
+	RetirementSystem   string  `json:"retirement_system"`
+	TargetPension      float64 `json:"target_pension"`
+	CurrentPension     float64 `json:"current_pension"`
+	CurrentHigh3       float64 `json:"current_high_3"`
+	RequiredHigh3      float64 `json:"required_high_3"`
+	RequiredRaisePct   float64 `json:"required_raise_pct"`
+	CurrentService     float64 `json:"current_service"`
+	AdditionalYears    float64 `json:"additional_years"`
+	Multiplier         float64 `json:"multiplier,omitempty"`
+}
+
+// EligibilityMilestone is the earliest date a retirement eligibility rule is satisfied
+type EligibilityMilestone struct {
+	Name           string    `json:"name"`
+	Type           string    `json:"type"` // immediate_unreduced, immediate_reduced, or deferred
+	Date           time.Time `json:"date"`
+	AgeYears       int       `json:"age_years"`
+	AgeMonths      int       `json:"age_months"`
+	ServiceYears   float64   `json:"service_years"`
+	SeparationDate time.Time `json:"separation_date,omitempty"` // Deferred only: earliest separation that preserves the annuity
+}
+
+// EligibilityReport lists retirement eligibility milestones in date order
+type EligibilityReport struct {
+	RetirementSystem string                 `json:"retirement_system"`
+	BirthDate        time.Time              `json:"birth_date"`
+	HireDate         time.Time              `json:"hire_date"`
+	Milestones       []EligibilityMilestone `json:"milestones"`
+}
+
+// IncomeComposition is each source's share (0 to 1) of gross income, excluding one-time lump sums
+// TSP includes cash reserve withdrawals; Other covers phased-retirement salary and other pensions.
+type IncomeComposition struct {
+	Pension        float64 `json:"pension"`
+	SocialSecurity float64 `json:"social_security"` // Including spousal benefits
+	Supplement     float64 `json:"supplement"`
+	TSP            float64 `json:"tsp"`
+	Other          float64 `json:"other,omitempty"`
+}
+
+// SimulationSummary summarizes Monte Carlo trials of a plan with random TSP returns
+// Percentiles are interpolated between ranks rather than taken at the nearest rank.
+type SimulationSummary struct {
+	Trials              int     `json:"trials"`
+	SurvivedTrials      int     `json:"survived_trials"` // Trials where the TSP lasted to the end of the projection
+	SuccessRate         float64 `json:"success_rate"`
+	EndingBalanceP10    float64 `json:"ending_balance_p10"`
+	EndingBalanceP25    float64 `json:"ending_balance_p25"`
+	EndingBalanceMedian float64 `json:"ending_balance_median"`
+	EndingBalanceP75    float64 `json:"ending_balance_p75"`
+	EndingBalanceP90    float64 `json:"ending_balance_p90"`
+}
+
+// StressTestResult compares a plan against its required spending as configured and under
+// a fixed set of adverse conditions
+type StressTestResult struct {
+	Conditions []string   `json:"conditions"`
+	Base       StressCase `json:"base"`
+	Stress     StressCase `json:"stress"`
+}
+
+// StressCase reports whether one run of a plan meets its required spending
+type StressCase struct {
+	MeetsSpending     bool    `json:"meets_spending"`
+	ShortfallYears    int     `json:"shortfall_years,omitempty"`
+	FirstShortfallAge int     `json:"first_shortfall_age,omitempty"`
+	TotalShortfall    float64 `json:"total_shortfall,omitempty"`
+	TSPDepletionAge   int     `json:"tsp_depletion_age,omitempty"`
+}
+
+// BreakEvenAnalysis compares the cumulative net income of two plans by year
+type BreakEvenAnalysis struct {
+	Labels        [2]string      `json:"labels"`
+	Rows          []BreakEvenRow `json:"rows"`
+	Leader        int            `json:"leader"`                   // Index of the plan ahead first, or -1 if they never differ
+	CrossoverYear int            `json:"crossover_year,omitempty"` // First year the other plan's cumulative income passes the leader's
+	CrossoverAge  int            `json:"crossover_age,omitempty"`
+}
+
+// BreakEvenRow is one year of cumulative net income for each plan, in label order
+type BreakEvenRow struct {
+	Year       int        `json:"year"`
+	Age        int        `json:"age"`
+	Cumulative [2]float64 `json:"cumulative_net_income"`
+}
+
+// PlanCheck is a quick eligibility and warnings health check of a plan
+type PlanCheck struct {
+	Status              string   `json:"status"` // PASS, WARN, or FAIL
+	Eligible            bool     `json:"eligible"`
+	RetirementAge       int      `json:"retirement_age"`
+	PensionReductionPct float64  `json:"pension_reduction_pct,omitempty"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
 // Intermediate calculation models
 type PensionCalculation struct {
 	BasePension      float64
 	ReductionPercent float64
+	DepositReduction float64 // Annual reduction for unpaid pre-October 1982 CSRS deposits
+	ExcessContributionRefund float64 // CSRS lump-sum refund of contributions for service beyond the 80% cap
 	AdjustedPension  float64
 	SurvivorCost     float64
 	FinalPension     float64
+	PhasedPension    float64 // Partial annuity paid during phased retirement
+	CompositePension float64 // Annuity at full retirement after phased retirement
+	CSRSShare        float64 // Transferees: share of the annuity from the CSRS component, which gets full COLAs
 }
 
 type SocialSecurityCalculation struct {
@@ -116,6 +331,7 @@ type SocialSecurityCalculation struct {
 	ClaimingAge    int
 	Adjustment     float64
 	MonthlyBenefit float64
+	Capped         bool // MonthlyBenefit was clamped to the statutory maximum
 }
 
 type FERSSupplementCalculation struct {
@@ -125,4 +341,15 @@ type FERSSupplementCalculation struct {
 	EndAge        int
 	FERSYears     float64
 	SSEstimate    float64
-}
\ No newline at end of file
+}
+
+// ScenarioBundle packages a configuration with its computed results so a scenario
+// can be shared and re-rendered exactly as the user saw it, without recomputation
+// The config is carried as YAML text, so it reads like the config file it came from.
+type ScenarioBundle struct {
+	BundleVersion string            `json:"bundle_version"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Config        Config            `json:"-"`
+	ConfigYAML    string            `json:"config"`
+	Results       RetirementResults `json:"results"`
+}
@@ -3,18 +3,26 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"rgehrsitz/ferex_cli/internal/models"
 	"rgehrsitz/ferex_cli/pkg/config"
 	"rgehrsitz/ferex_cli/pkg/calc"
 	"rgehrsitz/ferex_cli/pkg/output"
 )
 
 var (
-	cfgFile string
-	verbose bool
-	format  string
-	monthly bool
+	cfgFile   string
+	verbose   bool
+	quiet     bool
+	format    string
+	monthly   bool
+	profile   string
+	noUnicode bool
+	asOf      string
+	asOfDate  time.Time
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -36,6 +44,17 @@ Examples:
   ferex calc my-retirement.yaml
   ferex calc my-retirement.yaml --output results.csv
   ferex validate my-retirement.yaml`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if asOf == "" {
+			return nil
+		}
+		date, err := time.Parse("2006-01-02", asOf)
+		if err != nil {
+			return fmt.Errorf("invalid --as-of date %q: use YYYY-MM-DD", asOf)
+		}
+		asOfDate = date
+		return nil
+	},
 }
 
 // calcCmd represents the calculate command
@@ -50,7 +69,8 @@ Use 'ferex init' to generate a template configuration file.
 Examples:
   ferex calc retirement-plan.yaml
   ferex calc plan.yaml --output results.csv --format csv
-  ferex calc plan.yaml --verbose`,
+  ferex calc plan.yaml --verbose
+  ferex calc plan.yaml --profile pessimistic`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCalc,
 }
@@ -101,20 +121,160 @@ Examples:
 	RunE: runCompare,
 }
 
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle [config-file]",
+	Short: "Export a scenario as a shareable JSON bundle",
+	Long: `Calculate a scenario and export the input configuration, computed results,
+and assumptions as a single versioned JSON document.
+
+The bundle can be re-rendered later with 'ferex render' without recomputation,
+reproducing exactly what was seen when it was created.
+
+Examples:
+  ferex bundle plan.yaml -o bundle.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundle,
+}
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render [bundle-file]",
+	Short: "Render results from a scenario bundle",
+	Long: `Render the results stored in a scenario bundle without recomputing them.
+
+Examples:
+  ferex render bundle.json
+  ferex render bundle.json --format table --verbose`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRender,
+}
+
+// solvePensionCmd represents the solve-pension command
+var solvePensionCmd = &cobra.Command{
+	Use:   "solve-pension [config-file]",
+	Short: "Solve for the high-3 or service needed to reach a target pension",
+	Long: `Back-solve the high-3 salary, and separately the additional years of service,
+needed for the unreduced annual annuity to reach a target amount. The pension
+multiplier is held fixed at the value that applies to the configured retirement.
+
+Examples:
+  ferex solve-pension plan.yaml --target 40000`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSolvePension,
+}
+
+// earliestCmd represents the earliest command
+var earliestCmd = &cobra.Command{
+	Use:   "earliest [config-file]",
+	Short: "Find the earliest dates you can retire",
+	Long: `Find the earliest date each retirement eligibility rule is satisfied, assuming
+you keep working and accruing service from your hire date.
+
+Reports immediate unreduced, immediate reduced (MRA+10), and deferred milestones.
+
+Examples:
+  ferex earliest plan.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEarliest,
+}
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [config-file]",
+	Short: "Quick health check of a retirement plan",
+	Long: `Validate a configuration, run the eligibility rules and warnings, and print a
+PASS, WARN, or FAIL status with any warnings. No projections are generated.
+
+Exits with code 4 when retirement eligibility is not met.
+
+Examples:
+  ferex check plan.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheck,
+}
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate [config-file]",
+	Short: "Run Monte Carlo trials of random TSP returns",
+	Long: `Run the plan many times with random annual TSP returns drawn around the growth
+rate, and report how often the TSP lasts to the end of the projection along with
+percentiles of the ending balance. The same seed always reproduces the same trials.
+
+Examples:
+  ferex simulate plan.yaml
+  ferex simulate plan.yaml --trials 5000 --volatility 0.12`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSimulate,
+}
+
+// stressCmd represents the stress command
+var stressCmd = &cobra.Command{
+	Use:   "stress [config-file]",
+	Short: "Check whether a plan survives adverse conditions",
+	Long: `Run the plan as configured and again under a fixed set of adverse conditions:
+low TSP returns, high inflation, a Social Security cut in 2033, and (for married
+retirees) an early death leaving the survivor. Reports whether each run still meets
+the required spending in spending.annual_amount.
+
+Exits with code 4 when the plan fails under stress.
+
+Examples:
+  ferex stress plan.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStress,
+}
+
+// breakevenCmd represents the breakeven command
+var breakevenCmd = &cobra.Command{
+	Use:   "breakeven [config-a] [config-b]",
+	Short: "Find when one plan's cumulative net income overtakes another's",
+	Long: `Compare the cumulative net income of exactly two plans year by year, such as
+early and late retirement, and report the crossover year where the plan that starts
+behind overtakes the other. Ages are those of the first plan.
+
+Examples:
+  ferex breakeven retire-57.yaml retire-62.yaml`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBreakEven,
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ferex.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().StringVarP(&format, "format", "f", "table", "output format (table, json, csv, yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress success and informational messages")
+	rootCmd.PersistentFlags().StringVarP(&format, "format", "f", "table", "output format (table, json, ndjson, csv, yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&monthly, "monthly", "m", false, "display monthly amounts for budgeting")
+	rootCmd.PersistentFlags().BoolVar(&noUnicode, "no-unicode", false, "draw table graphics with ASCII characters only")
+	rootCmd.PersistentFlags().StringVar(&asOf, "as-of", "", "evaluate the plan as of this date (YYYY-MM-DD) instead of today")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "assumption profile (optimistic, base, pessimistic); explicit rates in the config take precedence")
 
 	// Add subcommands
 	rootCmd.AddCommand(calcCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(solvePensionCmd)
+	rootCmd.AddCommand(earliestCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(simulateCmd)
+	rootCmd.AddCommand(stressCmd)
+	rootCmd.AddCommand(breakevenCmd)
 
 	// calcCmd flags
 	calcCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
+	calcCmd.Flags().Bool("break-even-age", false, "show the survivor election break-even death age")
+	calcCmd.Flags().Bool("no-supplement", false, "leave the FERS supplement out of the projection")
+	calcCmd.Flags().Int("stride", 1, "show every Nth year in the projection table")
+	calcCmd.Flags().Bool("self-check", false, "verify the projection accounting identities before output")
+	calcCmd.Flags().Float64("target-net-income", 0, "solve for the fixed TSP withdrawal that yields this first-year net income")
+	calcCmd.Flags().Bool("verify", false, "cross-check the annuity against OPM's published formula")
+	calcCmd.Flags().Bool("csv-comments", false, "prefix CSV output with '#' lines recording the inputs and assumptions")
+	calcCmd.Flags().Bool("oneline", false, "print a single compact summary line instead of the full output")
+	calcCmd.Flags().Bool("timeline", false, "list the age each income source starts and stops")
 	
 	// initCmd flags
 	initCmd.Flags().StringP("template", "t", "basic", "template type (basic, advanced, csrs)")
@@ -125,32 +285,94 @@ func init() {
 	// compareCmd flags
 	compareCmd.Flags().StringSlice("ages", []string{"57", "62"}, "retirement ages to compare")
 	compareCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
+	compareCmd.Flags().Bool("no-supplement", false, "leave the FERS supplement out of the projection")
+	compareCmd.Flags().String("baseline", "", "retirement age the deltas are measured against (default: the first age)")
+	
+	// bundleCmd flags
+	bundleCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
+	
+	// renderCmd flags
+	renderCmd.Flags().StringP("output", "o", "", "output file (default: stdout)")
+	renderCmd.Flags().Bool("csv-comments", false, "prefix CSV output with '#' lines recording the inputs and assumptions")
+	
+	// solvePensionCmd flags
+	solvePensionCmd.Flags().Float64("target", 0, "target annual pension")
+	solvePensionCmd.MarkFlagRequired("target")
+	
+	// simulateCmd flags
+	simulateCmd.Flags().Int("trials", 1000, "number of Monte Carlo trials")
+	simulateCmd.Flags().Float64("volatility", 0.15, "standard deviation of annual TSP returns")
+	simulateCmd.Flags().Int64("seed", 1, "random seed")
+}
+
+// loadOptions returns the config load options set by the global flags
+// Migration notes go to stderr unless --quiet is set.
+func loadOptions() config.LoadOptions {
+	opts := config.LoadOptions{Profile: profile, AsOf: asOfDate}
+	if !quiet {
+		opts.Notes = os.Stderr
+	}
+	return opts
 }
 
 func runCalc(cmd *cobra.Command, args []string) error {
 	configFile := args[0]
 	
 	// Load configuration
-	cfg, err := config.LoadConfig(configFile)
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
 	}
 	
 	// Validate configuration
 	if err := config.ValidateConfig(cfg); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	if noSupplement, _ := cmd.Flags().GetBool("no-supplement"); noSupplement {
+		cfg.Retirement.DisableSupplement = true
 	}
 	
 	// Run calculations
 	calculator := calc.NewCalculator(cfg)
-	results, err := calculator.Calculate()
+	var results *models.RetirementResults
+	if target, _ := cmd.Flags().GetFloat64("target-net-income"); target > 0 {
+		results, err = calculator.SolveTargetNetIncome(target)
+	} else {
+		results, err = calculator.Calculate()
+	}
 	if err != nil {
-		return fmt.Errorf("calculation failed: %w", err)
+		return withExitCode(exitCalculation, fmt.Errorf("calculation failed: %w", err))
+	}
+	
+	if selfCheck, _ := cmd.Flags().GetBool("self-check"); selfCheck {
+		if err := calc.CheckAccounting(results.AnnualProjections); err != nil {
+			return withExitCode(exitCalculation, err)
+		}
+	}
+	
+	if verify, _ := cmd.Flags().GetBool("verify"); verify {
+		if err := calculator.VerifyPension(results.Summary.AnnualPension); err != nil {
+			results.Metadata.Warnings = append(results.Metadata.Warnings, err.Error())
+		}
 	}
 	
 	// Output results
 	outputFile, _ := cmd.Flags().GetString("output")
-	outputter := output.NewOutputter(format, outputFile, verbose, monthly)
+	breakEven, _ := cmd.Flags().GetBool("break-even-age")
+	stride, _ := cmd.Flags().GetInt("stride")
+	csvComments, _ := cmd.Flags().GetBool("csv-comments")
+	oneline, _ := cmd.Flags().GetBool("oneline")
+	timeline, _ := cmd.Flags().GetBool("timeline")
+	outputter := output.NewOutputter(format, outputFile, verbose, monthly || cfg.Output.Monthly).
+		WithBreakEvenAge(breakEven).
+		WithStride(stride).
+		WithDateFormat(cfg.Output.DateFormat).
+		WithCSVComments(csvComments).
+		WithSideBySide(cfg.Output.SideBySide).
+		WithTimeline(timeline || cfg.Output.Timeline).
+		WithOneline(oneline).
+		WithASCII(noUnicode)
 	
 	return outputter.OutputResults(results)
 }
@@ -171,7 +393,13 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	configFile := args[0]
 	fixInteractive, _ := cmd.Flags().GetBool("fix-interactive")
 	
-	return config.ValidateConfigFile(configFile, fixInteractive)
+	if err := config.ValidateConfigFile(configFile, fixInteractive, loadOptions()); err != nil {
+		return withExitCode(exitValidation, err)
+	}
+	if !quiet {
+		fmt.Printf("✓ Configuration file %s is valid\n", configFile)
+	}
+	return nil
 }
 
 func runCompare(cmd *cobra.Command, args []string) error {
@@ -180,15 +408,35 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	outputFile, _ := cmd.Flags().GetString("output")
 	
 	// Load base configuration
-	cfg, err := config.LoadConfig(configFile)
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if noSupplement, _ := cmd.Flags().GetBool("no-supplement"); noSupplement {
+		cfg.Retirement.DisableSupplement = true
 	}
 	
 	// Run comparison
 	comparison, err := calc.CompareRetirementAges(cfg, ages)
 	if err != nil {
-		return fmt.Errorf("comparison failed: %w", err)
+		return withExitCode(exitCalculation, fmt.Errorf("comparison failed: %w", err))
+	}
+	
+	if baseline, _ := cmd.Flags().GetString("baseline"); baseline != "" {
+		index := -1
+		for i, age := range ages {
+			if age == baseline {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("baseline age %s is not one of the compared ages", baseline)
+		}
+		if err := calc.SetComparisonBaseline(comparison, index); err != nil {
+			return err
+		}
 	}
 	
 	// Output results
@@ -196,9 +444,195 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	return outputter.OutputComparison(comparison)
 }
 
+func runBundle(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	outputFile, _ := cmd.Flags().GetString("output")
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if err := config.ValidateConfig(cfg); err != nil {
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	results, err := calc.NewCalculator(cfg).Calculate()
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("calculation failed: %w", err))
+	}
+	
+	bundle, err := output.NewBundle(cfg, results)
+	if err != nil {
+		return err
+	}
+	
+	outputter := output.NewOutputter("json", outputFile, false, false)
+	return outputter.OutputBundle(bundle)
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	bundleFile := args[0]
+	outputFile, _ := cmd.Flags().GetString("output")
+	
+	bundle, err := output.LoadBundle(bundleFile)
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load bundle: %w", err))
+	}
+	
+	csvComments, _ := cmd.Flags().GetBool("csv-comments")
+	outputter := output.NewOutputter(format, outputFile, verbose, monthly || bundle.Config.Output.Monthly).
+		WithDateFormat(bundle.Config.Output.DateFormat).
+		WithCSVComments(csvComments).
+		WithSideBySide(bundle.Config.Output.SideBySide).
+		WithTimeline(bundle.Config.Output.Timeline).
+		WithASCII(noUnicode)
+	return outputter.OutputResults(&bundle.Results)
+}
+
+func runSolvePension(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	target, _ := cmd.Flags().GetFloat64("target")
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if err := config.ValidateConfig(cfg); err != nil {
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	solution, err := calc.NewCalculator(cfg).SolvePensionTarget(target)
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("solve failed: %w", err))
+	}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	return outputter.OutputPensionSolution(solution)
+}
+
+func runEarliest(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	report, err := calc.NewCalculator(cfg).FindEligibilityMilestones()
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("eligibility search failed: %w", err))
+	}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	return outputter.OutputEligibility(report)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if err := config.ValidateConfig(cfg); err != nil {
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	check, err := calc.NewCalculator(cfg).CheckPlan()
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("plan check failed: %w", err))
+	}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	if err := outputter.OutputPlanCheck(check); err != nil {
+		return err
+	}
+	if check.Status == calc.CheckFail {
+		return withExitCode(exitCalculation, fmt.Errorf("retirement eligibility requirements are not met"))
+	}
+	return nil
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if err := config.ValidateConfig(cfg); err != nil {
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	trials, _ := cmd.Flags().GetInt("trials")
+	volatility, _ := cmd.Flags().GetFloat64("volatility")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	summary, err := calc.Simulate(cfg, trials, volatility, seed)
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("simulation failed: %w", err))
+	}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	return outputter.OutputSimulation(summary)
+}
+
+func runStress(cmd *cobra.Command, args []string) error {
+	configFile := args[0]
+	
+	cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+	if err != nil {
+		return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config: %w", err))
+	}
+	
+	if err := config.ValidateConfig(cfg); err != nil {
+		return withExitCode(exitValidation, fmt.Errorf("config validation failed: %w", err))
+	}
+	
+	result, err := calc.StressTest(cfg)
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("stress test failed: %w", err))
+	}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	if err := outputter.OutputStressTest(result); err != nil {
+		return err
+	}
+	if !result.Stress.MeetsSpending {
+		return withExitCode(exitCalculation, fmt.Errorf("plan does not meet required spending under stress"))
+	}
+	return nil
+}
+
+func runBreakEven(cmd *cobra.Command, args []string) error {
+	var configs [2]*models.Config
+	for i, configFile := range args {
+		cfg, err := config.LoadConfigWithOptions(configFile, loadOptions())
+		if err != nil {
+			return withExitCode(exitConfigLoad, fmt.Errorf("failed to load config %s: %w", configFile, err))
+		}
+		if err := config.ValidateConfig(cfg); err != nil {
+			return withExitCode(exitValidation, fmt.Errorf("config %s failed validation: %w", configFile, err))
+		}
+		configs[i] = cfg
+	}
+	
+	analysis, err := calc.BreakEven(configs[0], configs[1])
+	if err != nil {
+		return withExitCode(exitCalculation, fmt.Errorf("break-even analysis failed: %w", err))
+	}
+	analysis.Labels = [2]string{filepath.Base(args[0]), filepath.Base(args[1])}
+	
+	outputter := output.NewOutputter(format, "", verbose, monthly)
+	return outputter.OutputBreakEven(analysis)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
\ No newline at end of file
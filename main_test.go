@@ -0,0 +1,283 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"rgehrsitz/ferex_cli/internal/models"
+	"rgehrsitz/ferex_cli/pkg/calc"
+	"rgehrsitz/ferex_cli/pkg/config"
+)
+
+func TestExitCodeForValidationFailure(t *testing.T) {
+	cfg, err := config.GenerateTemplate("basic")
+	if err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	cfg.Personal.RetirementSystem = "FERS-RAE" // Not a valid retirement system
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	configFile := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	err = runCalc(calcCmd, []string{configFile})
+	if err == nil {
+		t.Fatal("Expected a validation error")
+	}
+	if code := exitCode(err); code != exitValidation {
+		t.Errorf("Expected exit code %d for a validation failure, got %d", exitValidation, code)
+	}
+}
+
+func TestExitCodeForMissingConfig(t *testing.T) {
+	err := runCalc(calcCmd, []string{filepath.Join(t.TempDir(), "missing.yaml")})
+	if code := exitCode(err); code != exitConfigLoad {
+		t.Errorf("Expected exit code %d for a missing config, got %d", exitConfigLoad, code)
+	}
+}
+
+func TestExitCodeForBadConfigSettings(t *testing.T) {
+	cfg, err := config.GenerateTemplate("basic")
+	if err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	configFile := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	profile = "bogus"
+	err = runCalc(calcCmd, []string{configFile})
+	profile = ""
+	if code := exitCode(err); code != exitValidation {
+		t.Errorf("Expected exit code %d for an unknown profile, got %d", exitValidation, code)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to load config: ") {
+		t.Errorf("Expected the load context on the error, got %v", err)
+	}
+
+	typo := strings.Replace(string(data), "\nretirement:", "\nretirment:", 1)
+	if err := os.WriteFile(configFile, []byte(typo), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if code := exitCode(runCalc(calcCmd, []string{configFile})); code != exitValidation {
+		t.Errorf("Expected exit code %d for an unknown key, got %d", exitValidation, code)
+	}
+
+	if err := os.WriteFile(configFile, []byte("personal: [unclosed"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if code := exitCode(runCalc(calcCmd, []string{configFile})); code != exitConfigLoad {
+		t.Errorf("Expected exit code %d for malformed YAML, got %d", exitConfigLoad, code)
+	}
+}
+
+// captureStdout returns everything written to stdout while fn runs
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := fn()
+	os.Stdout = stdout
+	w.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	return string(data), runErr
+}
+
+func TestCheckCommandStatus(t *testing.T) {
+	writeConfig := func(t *testing.T, retirementYear int) string {
+		cfg, err := config.GenerateTemplate("basic")
+		if err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		cfg.Retirement.TargetRetirementDate = time.Date(retirementYear, 3, 15, 0, 0, 0, 0, time.UTC)
+
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		configFile := filepath.Join(t.TempDir(), "plan.yaml")
+		if err := os.WriteFile(configFile, data, 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		return configFile
+	}
+
+	out, err := captureStdout(t, func() error { return runCheck(checkCmd, []string{writeConfig(t, 2029)}) })
+	if err != nil {
+		t.Fatalf("runCheck failed: %v", err)
+	}
+	if !strings.Contains(out, "PASS") {
+		t.Errorf("Expected PASS for an eligible age-62 plan, got:\n%s", out)
+	}
+
+	// Retiring at the MRA of 57 with 25 years is a reduced MRA+10 retirement
+	out, err = captureStdout(t, func() error { return runCheck(checkCmd, []string{writeConfig(t, 2024)}) })
+	if err != nil {
+		t.Fatalf("runCheck failed: %v", err)
+	}
+	if !strings.Contains(out, "WARN") || !strings.Contains(out, "reduced pension") {
+		t.Errorf("Expected a reduction warning for early retirement, got:\n%s", out)
+	}
+}
+
+func TestValidateQuietPrintsNothing(t *testing.T) {
+	cfg, err := config.GenerateTemplate("basic")
+	if err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	configFile := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	quiet = true
+	defer func() { quiet = false }()
+
+	out, err := captureStdout(t, func() error { return runValidate(validateCmd, []string{configFile}) })
+	if err != nil {
+		t.Fatalf("runValidate failed: %v", err)
+	}
+	if out != "" {
+		t.Errorf("Expected no output from a quiet successful validation, got %q", out)
+	}
+}
+
+func TestLoadedTotalYearsIsReconciled(t *testing.T) {
+	cfg, err := config.GenerateTemplate("basic")
+	if err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	cfg.Employment.HireDate = time.Date(2009, 3, 15, 0, 0, 0, 0, time.UTC)
+	cfg.Retirement.TargetRetirementDate = time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC)
+	cfg.Employment.CreditableService.MilitaryService = &models.MilitaryService{Years: 4, BoughtBack: true}
+
+	warnings := func(totalYears float64) []string {
+		cfg.Employment.CreditableService.TotalYears = totalYears
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		configFile := filepath.Join(t.TempDir(), "plan.yaml")
+		if err := os.WriteFile(configFile, data, 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		loaded, err := config.LoadConfig(configFile)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		results, err := calc.NewCalculator(loaded).Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		return results.Metadata.Warnings
+	}
+
+	reconciles := func(warnings []string) bool {
+		for _, warning := range warnings {
+			if strings.Contains(warning, "does not reconcile") {
+				return false
+			}
+		}
+		return true
+	}
+
+	// 20 calendar years plus 4 military years
+	if w := warnings(24); !reconciles(w) {
+		t.Errorf("Expected total_years 24 to reconcile, got %v", w)
+	}
+	if w := warnings(40); reconciles(w) {
+		t.Errorf("Expected a reconciliation warning for total_years 40 in the file, got %v", w)
+	}
+}
+
+// loadPlan writes cfg to a temporary file and loads it back through the config loader
+func loadPlan(t *testing.T, cfg *models.Config, opts config.LoadOptions) *models.Config {
+	t.Helper()
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	configFile := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err := config.LoadConfigWithOptions(configFile, opts)
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions failed: %v", err)
+	}
+	return loaded
+}
+
+func TestPessimisticProfileDepletesEarlier(t *testing.T) {
+	depletionAge := func(profile string) int {
+		cfg, err := config.GenerateTemplate("basic")
+		if err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		cfg.TSP.GrowthRate = nil
+		cfg.TSP.WithdrawalStrategy = "fixed_amount"
+		cfg.TSP.WithdrawalAmount = 45000
+		cfg.TSP.WithdrawalRate = 0
+		cfg.TSP.GrowthTiming = "begin_of_year"
+
+		results, err := calc.NewCalculator(loadPlan(t, cfg, config.LoadOptions{Profile: profile})).Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		return results.Summary.TSPProjectedDepletion
+	}
+
+	base := depletionAge("base")
+	pessimistic := depletionAge("pessimistic")
+
+	if base == 0 || pessimistic == 0 {
+		t.Fatalf("Expected TSP depletion under both profiles, got base=%d pessimistic=%d", base, pessimistic)
+	}
+	if pessimistic >= base {
+		t.Errorf("Expected pessimistic depletion before base, got pessimistic=%d base=%d", pessimistic, base)
+	}
+}
+
+func TestVerifyPensionAgreesForTemplates(t *testing.T) {
+	for _, name := range []string{"basic", "csrs"} {
+		cfg, err := config.GenerateTemplate(name)
+		if err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		calculator := calc.NewCalculator(loadPlan(t, cfg, config.LoadOptions{}))
+		results, err := calculator.Calculate()
+		if err != nil {
+			t.Fatalf("%s: Calculate failed: %v", name, err)
+		}
+		if err := calculator.VerifyPension(results.Summary.AnnualPension); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
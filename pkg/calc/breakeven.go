@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// BreakEven compares the cumulative net income of exactly two plans year by year and
+// finds the year the plan that starts behind overtakes the other, as when comparing
+// early and late retirement. Years before a plan's projection begins add nothing to it.
+func BreakEven(a, b *models.Config) (models.BreakEvenAnalysis, error) {
+	results, err := calculateScenarios([]*models.Config{a, b})
+	if err != nil {
+		return models.BreakEvenAnalysis{}, err
+	}
+
+	netByYear := [2]map[int]float64{{}, {}}
+	firstYear, lastYear := 0, 0
+	for i, result := range results {
+		for _, projection := range result.AnnualProjections {
+			netByYear[i][projection.Year] = projection.NetIncome
+			if firstYear == 0 || projection.Year < firstYear {
+				firstYear = projection.Year
+			}
+			lastYear = max(lastYear, projection.Year)
+		}
+	}
+
+	analysis := models.BreakEvenAnalysis{Leader: -1}
+	var cumulative [2]float64
+	birthYear := a.Personal.BirthDate.Year()
+	for year := firstYear; firstYear > 0 && year <= lastYear; year++ {
+		cumulative[0] += netByYear[0][year]
+		cumulative[1] += netByYear[1][year]
+		analysis.Rows = append(analysis.Rows, models.BreakEvenRow{
+			Year:       year,
+			Age:        year - birthYear,
+			Cumulative: cumulative,
+		})
+
+		// The first plan ahead leads; the crossover is the first year the other passes it
+		switch {
+		case analysis.Leader < 0 && cumulative[0] != cumulative[1]:
+			analysis.Leader = 0
+			if cumulative[1] > cumulative[0] {
+				analysis.Leader = 1
+			}
+		case analysis.Leader >= 0 && analysis.CrossoverYear == 0 &&
+			cumulative[1-analysis.Leader] > cumulative[analysis.Leader]:
+			analysis.CrossoverYear = year
+			analysis.CrossoverAge = year - birthYear
+		}
+	}
+
+	return analysis, nil
+}
@@ -1,20 +1,58 @@
 package calc
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"time"
 
 	"rgehrsitz/ferex_cli/internal/models"
 )
 
+// ErrIneligible reports a configuration that cannot produce an annuity; match with errors.Is
+var ErrIneligible = errors.New("not eligible for an annuity")
+
+// minimumAnnuityService is the creditable service required for any FERS or CSRS annuity
+const minimumAnnuityService = 5.0
+
 // Calculator handles retirement calculations
 type Calculator struct {
 	config *models.Config
+	now    time.Time // Date the plan is evaluated on, to detect a retirement already under way
 }
 
 // NewCalculator creates a new calculator instance
+// The plan is evaluated as of today unless the config sets assumptions.as_of.
 func NewCalculator(config *models.Config) *Calculator {
-	return &Calculator{config: config}
+	now := config.Assumptions.AsOf
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &Calculator{config: config, now: now}
+}
+
+// growthRate returns the assumed TSP growth rate, defaulting to 7%
+func (c *Calculator) growthRate() float64 {
+	if rate := c.config.TSP.GrowthRate; rate != nil {
+		return *rate
+	}
+	return 0.07
+}
+
+// inflationRate returns the configured inflation assumption, defaulting to 2.5%
+func (c *Calculator) inflationRate() float64 {
+	if rate := c.config.Assumptions.InflationRate; rate != nil {
+		return *rate
+	}
+	return 0.025
+}
+
+// colaRate returns the configured COLA assumption, defaulting to 2.5%
+func (c *Calculator) colaRate() float64 {
+	if rate := c.config.Assumptions.COLARate; rate != nil {
+		return *rate
+	}
+	return 0.025
 }
 
 // Calculate performs the complete retirement calculation
@@ -52,23 +90,72 @@ func (c *Calculator) Calculate() (*models.RetirementResults, error) {
 
 // calculatePension calculates the basic FERS/CSRS pension
 func (c *Calculator) calculatePension() (models.PensionCalculation, error) {
-	service := c.config.Employment.CreditableService.TotalYears
-	high3 := c.config.Employment.High3Salary
+	service := c.annuityServiceYears()
 	age := c.calculateAgeAtRetirement()
 
+	// Without 5 years of service there is no immediate or deferred annuity, only a refund
+	if service < minimumAnnuityService {
+		return models.PensionCalculation{}, fmt.Errorf("%w: %.1f years of service is below the %.0f-year minimum",
+			ErrIneligible, service, minimumAnnuityService)
+	}
+
+	pension := c.calculatePensionForService(service, age)
+	pension.ExcessContributionRefund = c.calculateExcessContributionRefund(service)
+
+	// Phased retirement pays half the annuity while working part-time, then a composite
+	// annuity adding credit for the half-time service worked during the phase
+	if phased := c.config.Retirement.PhasedRetirement; phased != nil {
+		phasedYears := float64(phased.Years)
+		full := c.calculatePensionForService(service+phasedYears*0.5, age+phased.Years)
+		pension.PhasedPension = pension.FinalPension * 0.5
+		pension.CompositePension = pension.PhasedPension + full.FinalPension*0.5
+	}
+
+	return pension, nil
+}
+
+// calculatePensionForService calculates the annuity for a given service and retirement age
+func (c *Calculator) calculatePensionForService(service float64, age int) models.PensionCalculation {
+	high3 := c.high3Salary()
+
 	var basePension float64
 	var reductionPct float64
+	var csrsShare float64
 
-	if c.config.Personal.RetirementSystem == "FERS" {
+	// The multiplier depends on age at separation; the reduction on age at commencement
+	startAge := c.commencementAge(age)
+	if transfer := c.config.Employment.CreditableService.Transfer; transfer != nil && c.config.Personal.RetirementSystem == "FERS" {
+		// Transferees add a CSRS component for CSRS service to a FERS component for FERS
+		// service; special provision eligibility depends on total service and applies to both
+		special := c.meetsSpecialProvision(age, service)
+		fersYears := c.fersServiceYears(service)
+		csrsComponent := csrsAnnuity(transfer.CSRSYears, high3, special)
+		fersComponent := high3 * c.fersMultiplier(service, age) * fersYears
+		if special {
+			fersComponent = specialProvisionFERSAnnuity(fersYears, transfer.CSRSYears, high3)
+		}
+		basePension = csrsComponent + fersComponent
+		reductionPct = c.calculateFERSReduction(startAge, service)
+		if basePension > 0 {
+			csrsShare = csrsComponent / basePension
+		}
+	} else if c.config.Personal.RetirementSystem == "FERS" {
 		basePension = c.calculateFERSPension(service, high3, age)
-		reductionPct = c.calculateFERSReduction(age, service)
+		reductionPct = c.calculateFERSReduction(startAge, service)
 	} else {
-		basePension = c.calculateCSRSPension(service, high3)
-		reductionPct = c.calculateCSRSReduction(age, service)
+		basePension = c.calculateCSRSPension(service, high3, age)
+		reductionPct = c.calculateCSRSReduction(startAge, service)
 	}
 
 	// Apply reduction
 	adjustedPension := basePension * (1 - reductionPct/100)
+	
+	// Unpaid pre-1982 CSRS deposits keep their service credit at the cost of a permanent reduction
+	depositReduction := 0.0
+	if c.config.Personal.RetirementSystem == "CSRS" {
+		depositReduction = math.Min(c.calculateDepositReduction(), adjustedPension)
+		adjustedPension -= depositReduction
+	}
 
 	// Apply survivor benefit reduction
 	survivorCost := c.calculateSurvivorBenefitCost(adjustedPension)
@@ -77,24 +164,92 @@ func (c *Calculator) calculatePension() (models.PensionCalculation, error) {
 	return models.PensionCalculation{
 		BasePension:      basePension,
 		ReductionPercent: reductionPct,
+		DepositReduction: depositReduction,
 		AdjustedPension:  adjustedPension,
 		SurvivorCost:     survivorCost,
 		FinalPension:     finalPension,
-	}, nil
+		CSRSShare:        csrsShare,
+	}
+}
+
+// commencementAge returns the age the annuity begins for a given separation age
+// A deferred annuity begins at the configured deferred start age instead.
+func (c *Calculator) commencementAge(separationAge int) int {
+	if c.config.Retirement.DeferredStartAge > separationAge {
+		return c.config.Retirement.DeferredStartAge
+	}
+	return separationAge
+}
+
+// isDeferredOrPostponed reports whether the annuity does not begin at separation
+// Covers deferred annuities and MRA+10 retirements with a postponed start. A postponed
+// start only applies while the retirement is a reduced MRA+10 one, so comparing a later
+// age that qualifies for an unreduced annuity treats it as immediate.
+func (c *Calculator) isDeferredOrPostponed() bool {
+	age := c.calculateAgeAtRetirement()
+	if c.config.Retirement.DeferredStartAge > age {
+		return true
+	}
+	early := c.config.Retirement.EarlyRetirement
+	return early != nil && early.Type == "MRA+10" && early.PostponedStart &&
+		c.calculateFERSReduction(age, c.annuityServiceYears()) > 0
 }
 
 // calculateFERSPension calculates basic FERS pension
+// age is the age at separation: the 1.1% multiplier requires being 62 when retiring,
+// so a deferred annuity commencing at 62 after an earlier separation still uses 1.0%.
+// The special provision formula applies only to a retirement that qualifies under it.
 func (c *Calculator) calculateFERSPension(service, high3 float64, age int) float64 {
-	var multiplier float64
-	
-	// Determine multiplier based on age and service
+	if c.meetsSpecialProvision(age, service) {
+		return specialProvisionFERSAnnuity(service, 0, high3)
+	}
+	return high3 * c.fersMultiplier(service, age) * service
+}
+
+// specialProvisionFERSAnnuity applies the FERS special provision formula to FERS service:
+// 1.7% for years within the first 20 of the career and 1% for each year after. priorYears
+// is service credited ahead of it, such as a transferee's CSRS years.
+func specialProvisionFERSAnnuity(years, priorYears, high3 float64) float64 {
+	enhanced := math.Min(years, math.Max(specialProvisionService-priorYears, 0))
+	return high3 * (0.017*enhanced + 0.01*(years-enhanced))
+}
+
+// Special provision employees may retire unreduced at 50 with 20 years, or at any age with 25
+const (
+	specialProvisionAge           = 50
+	specialProvisionService       = 20
+	specialProvisionAnyAgeService = 25
+)
+
+// meetsSpecialProvision reports whether a special provision employee qualifies for
+// the special provision immediate retirement at the given age and service
+func (c *Calculator) meetsSpecialProvision(age int, service float64) bool {
+	if !c.config.Personal.SpecialProvision {
+		return false
+	}
+	return (age >= specialProvisionAge && service >= specialProvisionService) ||
+		service >= specialProvisionAnyAgeService
+}
+
+// fersMultiplier returns the FERS multiplier for total service and age at separation
+func (c *Calculator) fersMultiplier(service float64, age int) float64 {
 	if age >= 62 && service >= 20 {
-		multiplier = 0.011 // 1.1% for age 62+ with 20+ years
-	} else {
-		multiplier = 0.01  // 1.0% for all other cases
+		return 0.011 // 1.1% for age 62+ with 20+ years
 	}
-	
-	return high3 * multiplier * service
+	return 0.01 // 1.0% for all other cases
+}
+
+// fersServiceYears returns the part of total service credited under FERS
+// For transferees, service beyond the configured total (e.g. phased retirement) is FERS service.
+func (c *Calculator) fersServiceYears(service float64) float64 {
+	transfer := c.config.Employment.CreditableService.Transfer
+	if transfer == nil {
+		return service
+	}
+	if transfer.FERSYears > 0 {
+		return transfer.FERSYears + service - c.config.Employment.CreditableService.TotalYears
+	}
+	return math.Max(service-transfer.CSRSYears, 0)
 }
 
 // calculateFERSReduction calculates early retirement reduction for FERS
@@ -106,8 +261,12 @@ func (c *Calculator) calculateFERSReduction(age int, service float64) float64 {
 	if age >= 60 && service >= 20 {
 		return 0 // Age 60 with 20+ years
 	}
+	if c.meetsSpecialProvision(age, service) {
+		return 0 // Special provision: 50 with 20+ years or 25+ years at any age
+	}
 	
-	// MRA + 30 has no reduction
+	// MRA + 30 has no reduction; eligibility is checked to the month elsewhere, so
+	// a whole-year age only needs to reach the year the MRA falls in
 	mra := c.calculateMRA()
 	if age >= mra && service >= 30 {
 		return 0
@@ -125,8 +284,31 @@ func (c *Calculator) calculateFERSReduction(age int, service float64) float64 {
 	return 0 // Should not reach here for eligible retirees
 }
 
-// calculateCSRSPension calculates basic CSRS pension
-func (c *Calculator) calculateCSRSPension(service, high3 float64) float64 {
+// CSRS annuities are capped at 80% of high-3, which the tiered formula reaches at
+// 41 years 11 months (10 years at the lower tiers plus 31.875 years at 2%)
+const (
+	csrsMaxPensionPct   = 0.80
+	csrsMaxServiceYears = 41.875
+)
+
+// excessRefundInterestRate is the interest credited on refunded CSRS excess contributions
+const excessRefundInterestRate = 0.03
+
+// calculateCSRSPension calculates basic CSRS pension for a separation age
+// The special provision formula applies only to a retirement that qualifies under it.
+func (c *Calculator) calculateCSRSPension(service, high3 float64, age int) float64 {
+	return csrsAnnuity(service, high3, c.meetsSpecialProvision(age, service))
+}
+
+// csrsAnnuity applies the CSRS formula, or the special provision formula when special is set
+func csrsAnnuity(service, high3 float64, special bool) float64 {
+	if special {
+		// 2.5% for the first 20 years and 2% for each year after
+		pension := high3 * (0.025*math.Min(service, specialProvisionService) +
+			0.02*math.Max(service-specialProvisionService, 0))
+		return math.Min(pension, high3*csrsMaxPensionPct)
+	}
+
 	// CSRS has a tiered calculation
 	var pension float64
 	
@@ -146,7 +328,72 @@ func (c *Calculator) calculateCSRSPension(service, high3 float64) float64 {
 		pension += remaining
 	}
 	
-	return pension
+	return math.Min(pension, high3*csrsMaxPensionPct)
+}
+
+// calculateExcessContributionRefund calculates the lump sum refunded at retirement for CSRS
+// contributions made on service beyond the 80% cap. Contributions are approximated at 7% of
+// high-3 per excess year, with interest compounded over the midpoint of the excess period.
+func (c *Calculator) calculateExcessContributionRefund(service float64) float64 {
+	if c.config.Personal.RetirementSystem != "CSRS" || c.config.Retirement.DisableExcessRefund {
+		return 0
+	}
+	excess := service - csrsMaxServiceYears
+	if excess <= 0 {
+		return 0
+	}
+	return excess * c.high3Salary() * csrsContributionRate * math.Pow(1+excessRefundInterestRate, excess/2)
+}
+
+// high3Salary returns the high-3 used for the annuity
+// The configured high-3 is held flat unless projection is enabled and it was measured
+// before the retirement date, in which case it grows with raises until retirement.
+func (c *Calculator) high3Salary() float64 {
+	employment := c.config.Employment
+	retirement := c.config.Retirement.TargetRetirementDate
+	if !employment.ProjectHigh3 || employment.High3AsOf.IsZero() || !retirement.After(employment.High3AsOf) {
+		return employment.High3Salary
+	}
+	
+	growth := employment.SalaryGrowthRate
+	if growth == 0 {
+		growth = c.inflationRate()
+	}
+	years := ServiceYears(employment.High3AsOf, retirement)
+	return employment.High3Salary * math.Pow(1+growth, years)
+}
+
+// annuityServiceYears returns creditable service including refunded periods restored by redeposit
+func (c *Calculator) annuityServiceYears() float64 {
+	return c.config.Employment.CreditableService.TotalYears + c.redepositedServiceYears()
+}
+
+// redepositedServiceYears returns refunded service restored by a paid redeposit
+func (c *Calculator) redepositedServiceYears() float64 {
+	service := 0.0
+	for _, period := range c.config.Employment.CreditableService.RefundedService {
+		if period.RedepositPaid {
+			service += ServiceYears(period.StartDate, period.EndDate)
+		}
+	}
+	return service
+}
+
+// depositCutoff is the date after which unpaid CSRS non-deduction service no longer
+// counts toward the annuity computation with a 10% reduction
+var depositCutoff = time.Date(1982, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// calculateDepositReduction calculates the annual annuity reduction for unpaid deposits
+// on non-deduction service performed before October 1, 1982: 10% of the deposit owed
+func (c *Calculator) calculateDepositReduction() float64 {
+	var reduction float64
+	for _, period := range c.config.Employment.CreditableService.NonDeductionPeriods {
+		if period.DepositPaid || !period.StartDate.Before(depositCutoff) {
+			continue
+		}
+		reduction += period.DepositOwed * 0.10
+	}
+	return reduction
 }
 
 // calculateCSRSReduction calculates early retirement reduction for CSRS
@@ -161,6 +408,9 @@ func (c *Calculator) calculateCSRSReduction(age int, service float64) float64 {
 	if age >= 55 && service >= 30 {
 		return 0 // No reduction for 55+30
 	}
+	if c.meetsSpecialProvision(age, service) {
+		return 0 // Special provision: 50 with 20+ years or 25+ years at any age
+	}
 	
 	// Early retirement reduction (simplified)
 	if age >= 55 && service >= 20 {
@@ -193,6 +443,25 @@ func (c *Calculator) calculateSurvivorBenefitCost(pension float64) float64 {
 	}
 }
 
+// calculateSurvivorAnnuity calculates the annual survivor annuity payable after the retiree's death
+// FERS pays 50% (full) or 25% (partial) of the unreduced annuity; CSRS pays 55% of the elected base.
+func (c *Calculator) calculateSurvivorAnnuity(pension models.PensionCalculation) float64 {
+	switch c.config.Retirement.SurvivorBenefit {
+	case "full":
+		if c.config.Personal.RetirementSystem == "FERS" {
+			return pension.BasePension * 0.50
+		}
+		return pension.BasePension * 0.55
+	case "partial":
+		if c.config.Personal.RetirementSystem == "FERS" {
+			return pension.BasePension * 0.25
+		}
+		return pension.BasePension * 0.5 * 0.55
+	default:
+		return 0
+	}
+}
+
 // calculateCSRSSurvivorCost calculates CSRS survivor benefit cost
 func (c *Calculator) calculateCSRSSurvivorCost(pension float64) float64 {
 	// CSRS: 2.5% of first $3600 + 10% of remainder
@@ -202,25 +471,43 @@ func (c *Calculator) calculateCSRSSurvivorCost(pension float64) float64 {
 	return 3600*0.025 + (pension-3600)*0.10
 }
 
-// calculateMRA calculates Minimum Retirement Age based on birth year
+// calculateMRA calculates Minimum Retirement Age in whole years based on birth year
+// It is the age the MRA falls in, so 56 and 6 months is 56; compare to the month with reachesMRA.
 func (c *Calculator) calculateMRA() int {
+	return c.calculateMRAMonths() / 12
+}
+
+// reachesMRA reports whether the age in months when the annuity begins meets the exact MRA
+func (c *Calculator) reachesMRA() bool {
+	return c.commencementAgeMonths() >= c.calculateMRAMonths()
+}
+
+// commencementAgeMonths returns the age in months when the annuity begins
+func (c *Calculator) commencementAgeMonths() int {
+	months := monthsBetween(c.config.Personal.BirthDate, c.config.Retirement.TargetRetirementDate)
+	if deferred := c.config.Retirement.DeferredStartAge * 12; deferred > months {
+		return deferred
+	}
+	return months
+}
+
+// calculateMRAMonths calculates the exact Minimum Retirement Age in months per the OPM schedule
+func (c *Calculator) calculateMRAMonths() int {
 	birthYear := c.config.Personal.BirthDate.Year()
 	
 	switch {
 	case birthYear < 1948:
-		return 55
+		return 55 * 12
 	case birthYear < 1953:
-		// 1948-1952: increases from 55 to 56 gradually, simplified to 56 for 1950+
-		if birthYear < 1950 {
-			return 55
-		}
-		return 56
+		// 1948-1952: increases from 55 by 2 months per birth year
+		return 55*12 + 2*(birthYear-1947)
 	case birthYear < 1965:
-		return 56
+		return 56 * 12
 	case birthYear < 1970:
-		return 57
+		// 1965-1969: increases from 56 by 2 months per birth year
+		return 56*12 + 2*(birthYear-1964)
 	default:
-		return 57
+		return 57 * 12
 	}
 }
 
@@ -270,18 +557,69 @@ func (c *Calculator) calculateSocialSecurity() models.SocialSecurityCalculation
 		monthlyBenefit = pia * adjustment
 	}
 	
+	// Clamp to the statutory maximum benefit at the claiming age
+	capped := false
+	if maxBenefit := c.maxSSBenefit(claimingAge); monthlyBenefit > maxBenefit {
+		monthlyBenefit = maxBenefit
+		adjustment = maxBenefit / pia
+		capped = true
+	}
+	
 	return models.SocialSecurityCalculation{
 		PIA:            pia,
 		ClaimingAge:    claimingAge,
 		Adjustment:     adjustment,
 		MonthlyBenefit: monthlyBenefit,
+		Capped:         capped,
 	}
 }
 
+// maxSSPIA is the 2025 maximum PIA for a worker with maximum taxable earnings every year
+const maxSSPIA = 4018.0
+
+// maxSSBenefit returns the maximum monthly Social Security benefit at a claiming age
+func (c *Calculator) maxSSBenefit(claimingAge int) float64 {
+	maxPIA := maxSSPIA
+	if c.config.SocialSecurity.MaxPIA > 0 {
+		maxPIA = c.config.SocialSecurity.MaxPIA
+	}
+	return maxPIA * c.calculateSSClaimingAdjustment(claimingAge)
+}
+
+// familyMaximumBendPoints are the 2025 bend points of the Social Security family maximum formula
+var familyMaximumBendPoints = [3]float64{1643, 2371, 3092}
+
+// calculateFamilyMaximum calculates the most that can be paid monthly on one worker's record
+// The formula takes 150%, 272%, 134%, and 175% of the PIA between the bend points, which
+// works out to between 150% and 188% of the PIA.
+func calculateFamilyMaximum(pia float64) float64 {
+	bends := familyMaximumBendPoints
+	maximum := 1.50 * math.Min(pia, bends[0])
+	if pia > bends[0] {
+		maximum += 2.72 * (math.Min(pia, bends[1]) - bends[0])
+	}
+	if pia > bends[1] {
+		maximum += 1.34 * (math.Min(pia, bends[2]) - bends[1])
+	}
+	if pia > bends[2] {
+		maximum += 1.75 * (pia - bends[2])
+	}
+	return maximum
+}
+
+// capAuxiliaryBenefits limits the combined benefits paid to family members on a worker's record
+// They share what the family maximum leaves after the worker's PIA; the worker's own benefit,
+// including delayed retirement credits, is never reduced.
+func capAuxiliaryBenefits(pia, auxiliary float64) float64 {
+	return math.Max(math.Min(auxiliary, calculateFamilyMaximum(pia)-pia), 0)
+}
+
+// ssFullRetirementAge is the Social Security full retirement age (simplified to 67)
+const ssFullRetirementAge = 67
+
 // calculateSSClaimingAdjustment calculates Social Security claiming age adjustment
 func (c *Calculator) calculateSSClaimingAdjustment(claimingAge int) float64 {
-	// Simplified - assumes FRA of 67
-	fra := 67
+	fra := ssFullRetirementAge
 	
 	if claimingAge == fra {
 		return 1.0 // 100% at FRA
@@ -310,18 +648,27 @@ func (c *Calculator) calculateFERSSupplement() models.FERSSupplementCalculation
 		}
 	}
 	
+	// The supplement is only payable with an immediate annuity, never a deferred or postponed one
+	if c.config.Retirement.DisableSupplement || c.isDeferredOrPostponed() {
+		return models.FERSSupplementCalculation{
+			Eligible: false,
+		}
+	}
+	
 	// Check eligibility (simplified)
-	service := c.config.Employment.CreditableService.TotalYears
+	service := c.annuityServiceYears()
 	age := c.calculateAgeAtRetirement()
-	mra := c.calculateMRA()
 	
 	eligible := false
-	if age >= mra && service >= 30 {
+	if c.reachesMRA() && service >= 30 {
 		eligible = true // MRA + 30
 	}
 	if age >= 60 && service >= 20 {
 		eligible = true // Age 60 + 20
 	}
+	if c.meetsSpecialProvision(age, service) {
+		eligible = true // Special provision retirees receive it before the MRA
+	}
 	
 	if !eligible {
 		return models.FERSSupplementCalculation{
@@ -329,10 +676,17 @@ func (c *Calculator) calculateFERSSupplement() models.FERSSupplementCalculation
 		}
 	}
 	
-	// Calculate supplement (simplified formula)
+	// Calculate supplement (simplified formula); the PIA includes non-FERS earnings, so a
+	// FERS-only age-62 estimate is the better base when given
 	ssEstimate := c.config.SocialSecurity.EstimatedPIA
-	fersYears := service // Simplified - assumes all service is FERS
+	if base := c.config.Retirement.SupplementSSBase; base > 0 {
+		ssEstimate = base
+	}
+	fersYears := c.fersServiceYears(service) // Transferees exclude CSRS service
 	supplement := (ssEstimate / 40) * fersYears
+	if override := c.config.Retirement.SupplementOverride; override > 0 {
+		supplement = override
+	}
 	
 	return models.FERSSupplementCalculation{
 		Eligible:        true,
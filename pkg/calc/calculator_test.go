@@ -1,6 +1,10 @@
 package calc
 
 import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,7 +38,7 @@ func createTestConfig() *models.Config {
 			TraditionalBalance: 400000,
 			RothBalance:        100000,
 			WithdrawalStrategy: "life_expectancy",
-			GrowthRate:         0.07,
+			GrowthRate:         models.Rate(0.07),
 		},
 		SocialSecurity: models.SocialSecurityInfo{
 			EstimatedPIA: 2800,
@@ -159,7 +163,7 @@ func TestTSPWithdrawals(t *testing.T) {
 	calc := NewCalculator(config)
 	
 	// Test life expectancy withdrawal at age 62
-	withdrawal := calc.calculateTSPWithdrawal(500000, 62)
+	withdrawal := calc.calculateTSPWithdrawal(500000, 400000, 62)
 	
 	// Should be based on life expectancy (approximately 27.4 years at age 62)
 	expectedWithdrawal := 500000.0 / 27.4
@@ -233,10 +237,10 @@ func TestMRACalculation(t *testing.T) {
 	config := createTestConfig()
 	calc := NewCalculator(config)
 	
-	// Test birth year 1967 should have MRA of 57
+	// Test birth year 1967 has an MRA of 56 and 6 months, which falls in age 56
 	mra := calc.calculateMRA()
-	if mra != 57 {
-		t.Errorf("Expected MRA 57 for birth year 1967, got %d", mra)
+	if mra != 56 {
+		t.Errorf("Expected MRA 56 for birth year 1967, got %d", mra)
 	}
 	
 	// Test different birth year
@@ -245,4 +249,2976 @@ func TestMRACalculation(t *testing.T) {
 	if mra != 56 {
 		t.Errorf("Expected MRA 56 for birth year 1955, got %d", mra)
 	}
-}
\ No newline at end of file
+}
+
+func TestCheckPlanComparesMRAInMonths(t *testing.T) {
+	// Born 1967: MRA is 56 and 6 months
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 30
+
+	config.Retirement.TargetRetirementDate = time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC) // 56 and 4 months
+	check, err := NewCalculator(config).CheckPlan()
+	if err != nil {
+		t.Fatalf("CheckPlan failed: %v", err)
+	}
+	if check.Eligible {
+		t.Error("Expected the calculator to flag a retiree two months short of the MRA as ineligible")
+	}
+
+	config.Retirement.TargetRetirementDate = time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC) // 56 and 6 months
+	check, err = NewCalculator(config).CheckPlan()
+	if err != nil {
+		t.Fatalf("CheckPlan failed: %v", err)
+	}
+	if !check.Eligible {
+		t.Error("Expected the calculator to treat a retiree at the MRA as eligible")
+	}
+}
+
+func TestSpecialProvisionRetirementAt50With20(t *testing.T) {
+	// Born 1967: age 52 with 22 years is below the MRA and short of every regular path
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 22
+	config.Retirement.TargetRetirementDate = time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+	config.Personal.SpecialProvision = true
+
+	check, err := NewCalculator(config).CheckPlan()
+	if err != nil {
+		t.Fatalf("CheckPlan failed: %v", err)
+	}
+	if !check.Eligible {
+		t.Error("Expected the calculator to treat a special provision employee at 52 with 22 years as eligible")
+	}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.PensionReductionPct != 0 {
+		t.Errorf("Expected no age reduction, got %.1f%%", results.Summary.PensionReductionPct)
+	}
+	// 1.7% for 20 years plus 1% for 2 years of the high-3, less 10% for the full survivor benefit
+	expected := config.Employment.High3Salary * (0.017*20 + 0.01*2) * 0.90
+	if math.Abs(results.Summary.AnnualPension-expected) > 0.01 {
+		t.Errorf("Expected annual pension %.2f, got %.2f", expected, results.Summary.AnnualPension)
+	}
+}
+
+func TestSpecialProvisionFormulaNeedsSpecialProvisionRetirement(t *testing.T) {
+	// A special provision employee retiring at 62 with 15 years qualifies only under the
+	// regular age 62 rule, so the regular 1% formula applies
+	config := createTestConfig()
+	config.Personal.SpecialProvision = true
+	config.Employment.CreditableService.TotalYears = 15
+	calc := NewCalculator(config)
+
+	if pension := calc.calculateFERSPension(15, 82000, 62); math.Abs(pension-82000*0.01*15) > 0.01 {
+		t.Errorf("Expected the regular formula %.2f, got %.2f", 82000*0.01*15, pension)
+	}
+	if pension := calc.calculateFERSPension(22, 82000, 52); math.Abs(pension-82000*(0.017*20+0.01*2)) > 0.01 {
+		t.Errorf("Expected the special provision formula %.2f, got %.2f", 82000*(0.017*20+0.01*2), pension)
+	}
+
+	report, err := calc.FindEligibilityMilestones()
+	if err != nil {
+		t.Fatalf("FindEligibilityMilestones failed: %v", err)
+	}
+	// Hired 1999-01-15: age 50 on 2017-03-15 comes before 20 years on 2019-01-15
+	expected := map[string]time.Time{
+		"Special provision at age 50 with 20 years": time.Date(2019, 1, 15, 0, 0, 0, 0, time.UTC),
+		"Special provision with 25 years":           time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for _, m := range report.Milestones {
+		if want, ok := expected[m.Name]; ok {
+			if !m.Date.Equal(want) {
+				t.Errorf("Milestone %q: expected %s, got %s", m.Name, want.Format("2006-01-02"), m.Date.Format("2006-01-02"))
+			}
+			delete(expected, m.Name)
+		}
+	}
+	for name := range expected {
+		t.Errorf("Missing milestone %q", name)
+	}
+}
+
+func TestMRAScheduleBoundaries(t *testing.T) {
+	// Official OPM MRA table: 2-month steps in the 1948-1952 and 1965-1969 bands; the
+	// whole-year MRA is the age the exact MRA falls in
+	testCases := []struct {
+		birthYear int
+		years     int
+		months    int
+	}{
+		{1947, 55, 0},
+		{1948, 55, 2},
+		{1949, 55, 4},
+		{1952, 55, 10},
+		{1953, 56, 0},
+		{1964, 56, 0},
+		{1965, 56, 2},
+		{1967, 56, 6},
+		{1969, 56, 10},
+		{1970, 57, 0},
+		{1975, 57, 0},
+	}
+
+	for _, tc := range testCases {
+		config := createTestConfig()
+		config.Personal.BirthDate = time.Date(tc.birthYear, 6, 1, 0, 0, 0, 0, time.UTC)
+		calc := NewCalculator(config)
+		if got, want := calc.calculateMRAMonths(), tc.years*12+tc.months; got != want {
+			t.Errorf("Birth year %d: expected MRA %dy%dm, got %dy%dm", tc.birthYear, tc.years, tc.months, got/12, got%12)
+		}
+		if got := calc.calculateMRA(); got != tc.years {
+			t.Errorf("Birth year %d: expected whole-year MRA %d, got %d", tc.birthYear, tc.years, got)
+		}
+	}
+}
+func TestSolvePensionTarget(t *testing.T) {
+	config := createTestConfig()
+	calc := NewCalculator(config)
+
+	target := 40000.0
+	solution, err := calc.SolvePensionTarget(target)
+	if err != nil {
+		t.Fatalf("SolvePensionTarget failed: %v", err)
+	}
+
+	// The solved high-3 should produce the target pension within a dollar
+	age := calc.calculateAgeAtRetirement()
+	service := config.Employment.CreditableService.TotalYears
+	pension := calc.calculateFERSPension(service, solution.RequiredHigh3, age)
+	if diff := pension - target; diff > 1 || diff < -1 {
+		t.Errorf("Solved high-3 %.2f produces pension %.2f, expected %.2f", solution.RequiredHigh3, pension, target)
+	}
+
+	// The extra service at the same multiplier should also reach the target
+	extended := config.Employment.High3Salary * solution.Multiplier * (service + solution.AdditionalYears)
+	if diff := extended - target; diff > 1 || diff < -1 {
+		t.Errorf("Solved service %.2f extra years produces pension %.2f, expected %.2f", solution.AdditionalYears, extended, target)
+	}
+
+	// Redeposited service counts toward the current annuity like any other credited service
+	config.Employment.CreditableService.RefundedService = []models.RefundedPeriod{{
+		StartDate:     time.Date(1992, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC),
+		RedepositPaid: true,
+	}}
+	redeposit := NewCalculator(config)
+	solution, err = redeposit.SolvePensionTarget(target)
+	if err != nil {
+		t.Fatalf("SolvePensionTarget failed: %v", err)
+	}
+	if solution.CurrentService != redeposit.annuityServiceYears() {
+		t.Errorf("Expected current service %.2f to include the redeposit, got %.2f", redeposit.annuityServiceYears(), solution.CurrentService)
+	}
+	if pension, _ := redeposit.calculatePension(); math.Abs(solution.CurrentPension-pension.BasePension) > 0.01 {
+		t.Errorf("Expected the current pension %.2f to match the projection's %.2f", solution.CurrentPension, pension.BasePension)
+	}
+}
+
+func TestSolvePensionTargetCSRS(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.RetirementSystem = "CSRS"
+	calc := NewCalculator(config)
+
+	target := 50000.0
+	solution, err := calc.SolvePensionTarget(target)
+	if err != nil {
+		t.Fatalf("SolvePensionTarget failed: %v", err)
+	}
+
+	service := config.Employment.CreditableService.TotalYears
+	age := calc.calculateAgeAtRetirement()
+	if pension := calc.calculateCSRSPension(service, solution.RequiredHigh3, age); pension < target-1 || pension > target+1 {
+		t.Errorf("Solved high-3 produces pension %.2f, expected %.2f", pension, target)
+	}
+	if pension := calc.calculateCSRSPension(service+solution.AdditionalYears, config.Employment.High3Salary, age); pension < target-1 || pension > target+1 {
+		t.Errorf("Solved service produces pension %.2f, expected %.2f", pension, target)
+	}
+}
+
+func TestEffectiveAndMarginalTaxRates(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 60000
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// First year: pension plus a $60k withdrawal and no Social Security lands in the 22% bracket
+	firstYear := results.AnnualProjections[0]
+	if diff := firstYear.MarginalTaxRate - 0.22; diff > 0.0001 || diff < -0.0001 {
+		t.Errorf("Expected 22%% marginal rate, got %.4f", firstYear.MarginalTaxRate)
+	}
+	if firstYear.EffectiveTaxRate <= 0 || firstYear.EffectiveTaxRate >= firstYear.MarginalTaxRate {
+		t.Errorf("Expected effective rate between 0 and the marginal rate, got %.4f", firstYear.EffectiveTaxRate)
+	}
+}
+
+func TestNetWithdrawalBasisGrossesUp(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 40000
+	config.TSP.WithdrawalBasis = "net"
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	firstYear := results.AnnualProjections[0]
+	if firstYear.TSPWithdrawal <= 40000 {
+		t.Fatalf("Expected a gross withdrawal above $40,000 for a $40,000 net basis, got %.2f", firstYear.TSPWithdrawal)
+	}
+
+	// The gross-up covers exactly the tax the withdrawal adds to the year's other income
+	config.TSP.WithdrawalBasis = "gross"
+	config.TSP.WithdrawalAmount = firstYear.TSPWithdrawal
+	grossed, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	config.TSP.WithdrawalAmount = 0.01
+	none, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	addedNet := grossed.AnnualProjections[0].NetIncome - none.AnnualProjections[0].NetIncome
+	if math.Abs(addedNet-40000) > 1 {
+		t.Errorf("Expected the grossed-up withdrawal to add $40,000 of net income, got %.2f", addedNet)
+	}
+}
+
+func TestWithdrawalCOLAGrowsFixedAmount(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 30000
+	config.TSP.GrowthRate = models.Rate(0.04)
+	config.TSP.GrowthTiming = "begin_of_year"
+
+	flat, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.TSP.WithdrawalCOLA = 0.025
+	growing, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	first, second := growing.AnnualProjections[0].TSPWithdrawal, growing.AnnualProjections[1].TSPWithdrawal
+	if first != 30000 || math.Abs(second-30750) > 0.01 {
+		t.Errorf("Expected withdrawals of $30,000 then $30,750, got %.2f then %.2f", first, second)
+	}
+	if growing.AnnualProjections[10].TSPWithdrawal <= flat.AnnualProjections[10].TSPWithdrawal {
+		t.Errorf("Expected the COLA withdrawal to exceed the flat one in later years, got %.2f vs %.2f",
+			growing.AnnualProjections[10].TSPWithdrawal, flat.AnnualProjections[10].TSPWithdrawal)
+	}
+
+	depletes := growing.Summary.TSPProjectedDepletion
+	if depletes == 0 || (flat.Summary.TSPProjectedDepletion != 0 && depletes >= flat.Summary.TSPProjectedDepletion) {
+		t.Errorf("Expected the COLA withdrawal to deplete the TSP sooner, got age %d vs %d for flat",
+			depletes, flat.Summary.TSPProjectedDepletion)
+	}
+}
+
+func TestServiceReconciliationWarning(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.HireDate = time.Date(2009, 3, 15, 0, 0, 0, 0, time.UTC)
+	config.Employment.CreditableService.MilitaryService = &models.MilitaryService{Years: 4, BoughtBack: true}
+
+	config.Employment.CreditableService.TotalYears = 24
+	if warnings := NewCalculator(config).generateWarnings(); containsWarning(warnings, "does not reconcile") {
+		t.Errorf("Expected 20 calendar years plus 4 military years to reconcile with 24, got %v", warnings)
+	}
+
+	config.Employment.CreditableService.TotalYears = 40
+	if warnings := NewCalculator(config).generateWarnings(); !containsWarning(warnings, "does not reconcile") {
+		t.Errorf("Expected a reconciliation warning for 40 years against 24 computed, got %v", warnings)
+	}
+}
+
+func TestHSAWithdrawalsOffsetHealthInsurance(t *testing.T) {
+	config := createTestConfig()
+	without, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.HSA = models.HSAInfo{Balance: 20000}
+	with, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, p := range with.AnnualProjections {
+		base := without.AnnualProjections[i]
+		if p.Age < 65 && p.HSAWithdrawal != 0 {
+			t.Errorf("Expected no HSA withdrawal before 65, got %.2f at age %d", p.HSAWithdrawal, p.Age)
+		}
+		if p.GrossIncome != base.GrossIncome || p.FederalTax != base.FederalTax {
+			t.Errorf("Expected HSA withdrawals to leave income and tax unchanged at age %d", p.Age)
+		}
+		if p.Age == 65 {
+			if p.HSAWithdrawal != p.HealthInsurance {
+				t.Errorf("Expected the HSA to pay the whole premium of %.2f at 65, got %.2f", p.HealthInsurance, p.HSAWithdrawal)
+			}
+			if math.Abs(base.TotalDeductions-p.TotalDeductions-p.HSAWithdrawal) > 0.01 {
+				t.Errorf("Expected deductions lower by the HSA withdrawal, got %.2f vs %.2f", p.TotalDeductions, base.TotalDeductions)
+			}
+		}
+	}
+
+	last := with.AnnualProjections[len(with.AnnualProjections)-1]
+	if last.HSAEndBalance != 0 {
+		t.Errorf("Expected the HSA to be spent down by the end of the projection, got %.2f", last.HSAEndBalance)
+	}
+}
+
+func TestTimelineListsIncomeSourceChanges(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.BirthDate = time.Date(1972, 3, 15, 0, 0, 0, 0, time.UTC) // retiring at 57
+	config.Employment.CreditableService.TotalYears = 30
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	ages := make(map[string]int)
+	for _, event := range results.Summary.Timeline {
+		ages[event.Event] = event.Age
+	}
+	if age, ok := ages["FERS supplement ends"]; !ok || age != 62 {
+		t.Errorf("Expected the FERS supplement to end at 62, got %v", results.Summary.Timeline)
+	}
+	if age, ok := ages["Social Security begins"]; !ok || age != config.SocialSecurity.ClaimingAge {
+		t.Errorf("Expected Social Security to begin at %d, got %v", config.SocialSecurity.ClaimingAge, results.Summary.Timeline)
+	}
+	for i := 1; i < len(results.Summary.Timeline); i++ {
+		if results.Summary.Timeline[i].Age < results.Summary.Timeline[i-1].Age {
+			t.Errorf("Expected the timeline in age order, got %v", results.Summary.Timeline)
+		}
+	}
+}
+
+func TestTimelineListsRMDsOnlyWhenDue(t *testing.T) {
+	rmdEvent := func(config *models.Config) (int, bool) {
+		results, err := NewCalculator(config).Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		for _, event := range results.Summary.Timeline {
+			if event.Event == "Required minimum distributions begin" {
+				return event.Age, true
+			}
+		}
+		return 0, false
+	}
+
+	config := createTestConfig()
+	if age, ok := rmdEvent(config); !ok || age != NewCalculator(config).rmdStartAge() {
+		t.Errorf("Expected RMDs to begin at %d, got %d (listed: %v)", NewCalculator(config).rmdStartAge(), age, ok)
+	}
+
+	// Roth balances have no RMD
+	config.TSP.RothBalance += config.TSP.TraditionalBalance
+	config.TSP.TraditionalBalance = 0
+	if _, ok := rmdEvent(config); ok {
+		t.Error("Expected no RMD event for an all-Roth TSP")
+	}
+
+	// Nor does the projection list one after the retiree's death
+	config = createTestConfig()
+	config.Personal.AssumedDeathAge = 70
+	if _, ok := rmdEvent(config); ok {
+		t.Error("Expected no RMD event when the retiree dies before RMDs begin")
+	}
+}
+
+func TestSupplementEarningsTestStartsAtMRAForSpecialProvision(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SupplementEarnings = 43400 // $20,000 over the limit
+	fersup := models.FERSSupplementCalculation{Eligible: true, MonthlyAmount: 1500, StartAge: 50, EndAge: 62}
+	mra := NewCalculator(config).calculateMRA()
+	subMRA := mra - 3
+
+	regular := NewCalculator(config).calculateFERSSupplementIncome(fersup, subMRA)
+	if regular != 8000 {
+		t.Errorf("Expected a regular FERS supplement of $18,000 reduced to $8,000 at %d, got %.2f", subMRA, regular)
+	}
+
+	config.Personal.SpecialProvision = true
+	leo := NewCalculator(config)
+	if got := leo.calculateFERSSupplementIncome(fersup, subMRA); got != 18000 {
+		t.Errorf("Expected no earnings test for a LEO before the MRA at %d, got %.2f", subMRA, got)
+	}
+	if got := leo.calculateFERSSupplementIncome(fersup, mra); got != 8000 {
+		t.Errorf("Expected the earnings test to apply to a LEO from the MRA at %d, got %.2f", mra, got)
+	}
+	if warnings := leo.generateWarnings(); !containsWarning(warnings, "not included in projected income") {
+		t.Errorf("Expected a warning that the wages are not projected, got %v", warnings)
+	}
+}
+
+func TestSupplementEarningsLimitIndexedWithBrackets(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SupplementEarnings = 43400
+	config.TaxInfo.IndexBrackets = true
+	config.Assumptions.InflationRate = models.Rate(0.03)
+	fersup := models.FERSSupplementCalculation{Eligible: true, MonthlyAmount: 1500, StartAge: 50, EndAge: 62}
+
+	calc := NewCalculator(config)
+	age := 60
+	limit := supplementEarningsLimit * calc.taxIndexFactor(age)
+	expected := 18000 - (43400-limit)/2
+	if got := calc.calculateFERSSupplementIncome(fersup, age); math.Abs(got-expected) > 0.01 {
+		t.Errorf("Expected the earnings limit to grow to $%.0f, leaving a supplement of %.2f, got %.2f", limit, expected, got)
+	}
+}
+
+func TestSurvivorSSIncome(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.AssumedDeathAge = 70
+	config.SocialSecurity.SurvivorSSClaimingAge = 67
+	calc := NewCalculator(config)
+	ss := models.SocialSecurityCalculation{ClaimingAge: 67, MonthlyBenefit: 3000}
+	cola := calc.colaRate()
+
+	// The retiree collects their own benefit until death, then the survivor takes it over
+	if own, expected := calc.calculateSSIncome(ss, 69), 36000*math.Pow(1+cola, 2); math.Abs(own-expected) > 0.01 {
+		t.Errorf("Expected the retiree's own benefit %.2f at 69, got %.2f", expected, own)
+	}
+	if survivor, expected := calc.calculateSSIncome(ss, 70), 36000*math.Pow(1+cola, 3); math.Abs(survivor-expected) > 0.01 {
+		t.Errorf("Expected the survivor to receive the full benefit %.2f at the death age, got %.2f", expected, survivor)
+	}
+
+	// A survivor born in September 1975 reaches 60 when the retiree would be 68; dying before
+	// claiming leaves the PIA, reduced to 71.5%, paid from the September birthday
+	config.Personal.AssumedDeathAge = 66
+	config.Personal.SpouseBirthDate = time.Date(1975, 9, 1, 0, 0, 0, 0, time.UTC)
+	config.SocialSecurity.SurvivorSSClaimingAge = 60
+	calc = NewCalculator(config)
+	if got := calc.calculateSSIncome(ss, 67); got != 0 {
+		t.Errorf("Expected no survivor benefit before the survivor reaches 60, got %.2f", got)
+	}
+	annual := config.SocialSecurity.EstimatedPIA * 12 * 0.715
+	if got, expected := calc.calculateSurvivorSSIncome(ss, 68), annual*math.Pow(1+cola, 2)*4/12; math.Abs(got-expected) > 0.01 {
+		t.Errorf("Expected four months of survivor benefit %.2f in the claiming year, got %.2f", expected, got)
+	}
+	if got, expected := calc.calculateSurvivorSSIncome(ss, 69), annual*math.Pow(1+cola, 3); math.Abs(got-expected) > 0.01 {
+		t.Errorf("Expected a full year of survivor benefit %.2f after the claiming year, got %.2f", expected, got)
+	}
+}
+
+func TestUncollectedSocialSecurityWarning(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.ClaimingAge = 70
+	config.Personal.AssumedDeathAge = 69
+
+	if warnings := NewCalculator(config).generateWarnings(); !containsWarning(warnings, "your own benefit is never collected") {
+		t.Errorf("Expected a warning that Social Security is never collected, got %v", warnings)
+	}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, p := range results.AnnualProjections {
+		if !p.RetireeDeceased && p.SocialSecurityIncome > 0 {
+			t.Fatalf("Expected no Social Security of your own before death at 69, got $%.2f at %d", p.SocialSecurityIncome, p.Age)
+		}
+	}
+
+	config.Personal.AssumedDeathAge = 71
+	if warnings := NewCalculator(config).generateWarnings(); containsWarning(warnings, "never collected") {
+		t.Errorf("Did not expect a warning when the benefit starts before death, got %v", warnings)
+	}
+}
+
+func TestSurvivorBenefitWithoutSpouseWarning(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.MaritalStatus = "single"
+
+	warnings := NewCalculator(config).generateWarnings()
+	if !containsWarning(warnings, "Survivor benefit elected without a spouse") {
+		t.Errorf("Expected survivor benefit warning for single retiree, got %v", warnings)
+	}
+
+	config.Personal.MaritalStatus = "married"
+	warnings = NewCalculator(config).generateWarnings()
+	if containsWarning(warnings, "Survivor benefit elected without a spouse") {
+		t.Errorf("Did not expect survivor benefit warning for married retiree, got %v", warnings)
+	}
+}
+
+func TestNoSurvivorAnnuityWarnsSpouseLosesFEHB(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.MaritalStatus = "married"
+	config.Personal.AssumedDeathAge = 80
+	config.Retirement.SurvivorBenefit = "none"
+
+	calculator := NewCalculator(config)
+	if !containsWarning(calculator.generateWarnings(), "spouse loses FEHB eligibility") {
+		t.Errorf("Expected a warning that the spouse loses FEHB, got %v", calculator.generateWarnings())
+	}
+	if premium := calculator.calculateHealthInsurance(80); premium != 0 {
+		t.Errorf("Expected no FEHB premium after death without a survivor annuity, got %.2f", premium)
+	}
+
+	// Only a current spouse can lose FEHB; an unset or divorced status has no one to warn about
+	for _, status := range []string{"", "single", "divorced", "widowed"} {
+		config.Personal.MaritalStatus = status
+		if containsWarning(NewCalculator(config).generateWarnings(), "spouse loses FEHB eligibility") {
+			t.Errorf("Did not expect a spouse FEHB warning for marital status %q", status)
+		}
+	}
+	config.Personal.MaritalStatus = "married"
+
+	// With a survivor annuity, the survivor's own premium replaces the retiree's
+	config.Retirement.SurvivorBenefit = "full"
+	config.HealthInsurance.SurvivorPremium = 3000
+	calculator = NewCalculator(config)
+	if containsWarning(calculator.generateWarnings(), "spouse loses FEHB eligibility") {
+		t.Error("Did not expect the FEHB warning with a survivor annuity elected")
+	}
+	if premium := calculator.calculateHealthInsurance(80); premium != 3000 {
+		t.Errorf("Expected the survivor's $3000 premium after death, got %.2f", premium)
+	}
+	if premium := calculator.calculateHealthInsurance(79); premium == 3000 {
+		t.Error("Expected the retiree's premium before death")
+	}
+
+	// The retiree's FEGLI premium ends with their death
+	if premium := calculator.calculateLifeInsurance(79); premium <= 0 {
+		t.Error("Expected a FEGLI premium before death")
+	}
+	if premium := calculator.calculateLifeInsurance(80); premium != 0 {
+		t.Errorf("Expected no FEGLI premium after death, got %.2f", premium)
+	}
+}
+
+func TestCustomScheduleWithdrawals(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "custom_schedule"
+	config.TSP.WithdrawalSchedule = map[int]float64{62: 40000, 63: 35000}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	expected := map[int]float64{62: 40000, 63: 35000, 64: 0}
+	for _, projection := range results.AnnualProjections {
+		want, ok := expected[projection.Age]
+		if !ok {
+			continue
+		}
+		if projection.TSPWithdrawal != want {
+			t.Errorf("Age %d: expected withdrawal %.0f, got %.2f", projection.Age, want, projection.TSPWithdrawal)
+		}
+	}
+}
+
+func TestLifetimeIncomeNPVBelowNominal(t *testing.T) {
+	config := createTestConfig()
+	config.Assumptions.DiscountRate = 0.03
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	summary := results.Summary
+	if summary.LifetimeIncomeNPV <= 0 || summary.LifetimeIncomeNPV >= summary.LifetimeIncome {
+		t.Errorf("Expected NPV between 0 and the nominal %.2f, got %.2f", summary.LifetimeIncome, summary.LifetimeIncomeNPV)
+	}
+}
+
+func TestInterpolatedPercentile(t *testing.T) {
+	// The median of an even count lies halfway between the middle two outcomes
+	if median := interpolatedPercentile([]float64{100, 200, 300, 400}, 0.5); median != 250 {
+		t.Errorf("Expected interpolated median 250, got %.2f", median)
+	}
+	// The 10th percentile of five outcomes is 40% of the way from the first to the second
+	if p10 := interpolatedPercentile([]float64{0, 100, 200, 300, 400}, 0.10); math.Abs(p10-40) > 1e-9 {
+		t.Errorf("Expected interpolated 10th percentile 40, got %.2f", p10)
+	}
+
+	summary := summarizeSimulation([]float64{400, 0, 300, 100}, 3)
+	if summary.Trials != 4 || summary.SurvivedTrials != 3 || summary.SuccessRate != 0.75 {
+		t.Errorf("Expected 3 of 4 trials surviving, got %+v", summary)
+	}
+	if summary.EndingBalanceMedian != 200 {
+		t.Errorf("Expected median ending balance 200 from unsorted outcomes, got %.2f", summary.EndingBalanceMedian)
+	}
+}
+
+func TestSimulateIsReproducible(t *testing.T) {
+	config := createTestConfig()
+
+	first, err := Simulate(config, 50, 0.15, 7)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	second, err := Simulate(config, 50, 0.15, 7)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Expected the same seed to reproduce the same summary, got %+v and %+v", first, second)
+	}
+	if first.Trials != 50 || first.SurvivedTrials > first.Trials {
+		t.Errorf("Expected 50 trials with at most 50 surviving, got %+v", first)
+	}
+	if first.EndingBalanceP10 > first.EndingBalanceMedian || first.EndingBalanceMedian > first.EndingBalanceP90 {
+		t.Errorf("Expected ordered percentiles, got %+v", first)
+	}
+}
+
+func TestStressTestFailsMarginalPlan(t *testing.T) {
+	config := createTestConfig()
+	config.Spending.AnnualAmount = 25000
+
+	result, err := StressTest(config)
+	if err != nil {
+		t.Fatalf("StressTest failed: %v", err)
+	}
+	if !result.Base.MeetsSpending {
+		t.Errorf("Expected the marginal plan to meet spending as configured, got %+v", result.Base)
+	}
+	if result.Stress.MeetsSpending {
+		t.Errorf("Expected the marginal plan to fall short under stress, got %+v", result.Stress)
+	}
+
+	config.Spending.AnnualAmount = 0
+	if _, err := StressTest(config); err == nil {
+		t.Error("Expected an error without required spending to test against")
+	}
+}
+
+func TestBreakEvenEarlyVersusLateRetirement(t *testing.T) {
+	late := createTestConfig()
+	early := createTestConfig()
+	if err := applyRetirementAgeOverride(early, "57"); err != nil {
+		t.Fatalf("applyRetirementAgeOverride failed: %v", err)
+	}
+	early.Retirement.EarlyRetirement = &models.EarlyRetirementInfo{Type: "MRA+10"}
+
+	analysis, err := BreakEven(early, late)
+	if err != nil {
+		t.Fatalf("BreakEven failed: %v", err)
+	}
+	if analysis.Leader != 0 {
+		t.Fatalf("Expected the early retirement to lead at first, got leader %d", analysis.Leader)
+	}
+	if analysis.CrossoverAge <= 62 {
+		t.Fatalf("Expected the late retirement to overtake after 62, got crossover age %d", analysis.CrossoverAge)
+	}
+
+	for _, row := range analysis.Rows {
+		if overtaken := row.Cumulative[1] > row.Cumulative[0]; overtaken != (row.Year >= analysis.CrossoverYear) {
+			t.Errorf("Expected the late retirement ahead only from %d, got %+v", analysis.CrossoverYear, row)
+			break
+		}
+	}
+}
+
+func TestRothConversionsReportedBySource(t *testing.T) {
+	config := createTestConfig()
+	base, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.RothConversions = []models.RothConversion{
+		{Age: 63, Amount: 20000, Source: "tsp"},
+		{Age: 64, Amount: 15000, Source: "ira"},
+		{Age: 64, Amount: 5000, Source: "tsp"},
+	}
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if results.Summary.TSPRothConversions != 25000 || results.Summary.IRARothConversions != 15000 {
+		t.Errorf("Expected $25,000 TSP and $15,000 IRA conversions, got $%.2f and $%.2f",
+			results.Summary.TSPRothConversions, results.Summary.IRARothConversions)
+	}
+
+	// Both kinds are taxed in their year without adding spendable income
+	for i, age := range []int{63, 64} {
+		year, before := results.AnnualProjections[i+1], base.AnnualProjections[i+1]
+		if year.Age != age {
+			t.Fatalf("Expected age %d, got %d", age, year.Age)
+		}
+		if year.FederalTax <= before.FederalTax {
+			t.Errorf("Expected conversions to raise the age %d federal tax above $%.2f, got $%.2f", age, before.FederalTax, year.FederalTax)
+		}
+		if year.GrossIncome != before.GrossIncome {
+			t.Errorf("Expected conversions not to change gross income at %d, got $%.2f vs $%.2f", age, year.GrossIncome, before.GrossIncome)
+		}
+	}
+	if first := results.AnnualProjections[1]; first.TSPRothConversion != 20000 || first.IRARothConversion != 0 {
+		t.Errorf("Expected only a $20,000 TSP conversion at 63, got %+v", first)
+	}
+}
+
+func TestConvertedTSPMoneyIsNotTaxedAgain(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.TraditionalBalance = 100000
+	config.TSP.RothBalance = 400000
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 30000
+	config.RothConversions = []models.RothConversion{{Age: 62, Amount: 200000, Source: "tsp"}}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// The age-62 conversion moves the whole remaining Traditional balance to Roth, so later
+	// withdrawals are untaxed and no RMD is forced
+	first := results.AnnualProjections[0]
+	if first.TaxableTSPWithdrawal != 30000 || first.TSPRothConversion <= 0 {
+		t.Fatalf("Expected a taxable $30,000 withdrawal and a conversion at 62, got %+v", first)
+	}
+	for _, p := range results.AnnualProjections[1:] {
+		if p.TaxableTSPWithdrawal != 0 {
+			t.Errorf("Expected no taxable withdrawal after converting everything at 62, got %.2f at %d", p.TaxableTSPWithdrawal, p.Age)
+		}
+		if p.TSPWithdrawal > 30000+0.01 && p.TSPStartBalance > 30000 {
+			t.Errorf("Expected no RMD on converted money, got a withdrawal of %.2f at %d", p.TSPWithdrawal, p.Age)
+		}
+	}
+}
+
+func TestIncomeCompositionSumsToOne(t *testing.T) {
+	config := createTestConfig()
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for name, mix := range map[string]models.IncomeComposition{
+		"first year": results.Summary.FirstYearIncomeMix,
+		"lifetime":   results.Summary.LifetimeIncomeMix,
+	} {
+		total := mix.Pension + mix.SocialSecurity + mix.Supplement + mix.TSP + mix.Other
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("Expected the %s income mix to sum to 100%%, got %.4f%%", name, total*100)
+		}
+	}
+	if mix := results.Summary.FirstYearIncomeMix; mix.Pension <= 0 || mix.TSP <= 0 {
+		t.Errorf("Expected pension and TSP shares in the first year, got %+v", mix)
+	}
+}
+
+func TestAnnualLeavePayoutIsTaxableFirstYear(t *testing.T) {
+	config := createTestConfig()
+
+	before, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.Employment.UnusedAnnualLeaveHours = 240
+	after, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	expected := 240 * config.Employment.High3Salary / workHoursPerYear
+	first := after.AnnualProjections[0]
+	if math.Abs(first.AnnualLeavePayout-expected) > 0.01 {
+		t.Errorf("Expected a payout of %.2f, got %.2f", expected, first.AnnualLeavePayout)
+	}
+	if math.Abs(first.GrossIncome-before.AnnualProjections[0].GrossIncome-expected) > 0.01 {
+		t.Error("Expected the payout to be added to first-year gross income")
+	}
+	if first.FederalTax <= before.AnnualProjections[0].FederalTax {
+		t.Error("Expected the payout to raise first-year federal tax")
+	}
+	if after.AnnualProjections[1].AnnualLeavePayout != 0 {
+		t.Error("Expected the payout only in the first year")
+	}
+
+	// The one-time payout is left out of the recurring first-year figures
+	if math.Abs(after.Summary.FirstYearIncome-before.Summary.FirstYearIncome) > 0.01 {
+		t.Errorf("Expected first-year income %.2f without the payout, got %.2f",
+			before.Summary.FirstYearIncome, after.Summary.FirstYearIncome)
+	}
+	if math.Abs(after.Summary.ReplacementRatio-before.Summary.ReplacementRatio) > 1e-9 ||
+		math.Abs(after.Summary.NetReplacementRatio-before.Summary.NetReplacementRatio) > 1e-9 {
+		t.Errorf("Expected replacement ratios without the payout, got %.4f and %.4f against %.4f and %.4f",
+			after.Summary.ReplacementRatio, after.Summary.NetReplacementRatio,
+			before.Summary.ReplacementRatio, before.Summary.NetReplacementRatio)
+	}
+	if after.Summary.AnnualPension != before.Summary.AnnualPension {
+		t.Error("Expected annual leave not to change the annuity")
+	}
+}
+
+func TestTSPTaxTreatmentShortcut(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 30000
+
+	config.TSP.TaxTreatment = "all_roth"
+	roth, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.TSP.TaxTreatment = "all_traditional"
+	traditional, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// The pension alone is taxed the same either way, so the difference is the TSP's tax
+	for i := range roth.AnnualProjections[:5] {
+		rothYear, traditionalYear := roth.AnnualProjections[i], traditional.AnnualProjections[i]
+		if rothYear.TaxableTSPWithdrawal != 0 {
+			t.Errorf("Age %d: expected no taxable withdrawal with all_roth, got %.2f", rothYear.Age, rothYear.TaxableTSPWithdrawal)
+		}
+		if traditionalYear.TaxableTSPWithdrawal != traditionalYear.TSPWithdrawal {
+			t.Errorf("Age %d: expected the full withdrawal taxable with all_traditional, got %.2f of %.2f",
+				traditionalYear.Age, traditionalYear.TaxableTSPWithdrawal, traditionalYear.TSPWithdrawal)
+		}
+		if traditionalYear.FederalTax <= rothYear.FederalTax {
+			t.Errorf("Age %d: expected all_traditional to owe more federal tax than all_roth", rothYear.Age)
+		}
+	}
+
+	// With no TSP taxable income, federal tax matches a plan that withdraws nothing
+	config.TSP.TaxTreatment = ""
+	config.TSP.WithdrawalAmount = 0
+	config.TSP.WithdrawalStrategy = "custom_schedule"
+	config.TSP.WithdrawalSchedule = map[int]float64{}
+	none, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if math.Abs(none.AnnualProjections[0].FederalTax-roth.AnnualProjections[0].FederalTax) > 0.01 {
+		t.Errorf("Expected all_roth federal tax %.2f to equal the no-withdrawal tax %.2f",
+			roth.AnnualProjections[0].FederalTax, none.AnnualProjections[0].FederalTax)
+	}
+}
+
+func TestPastRetirementDateProjectsAsAlreadyRetired(t *testing.T) {
+	// Retired two years before the evaluation date, at 62 in 2029
+	config := createTestConfig()
+	calculator := NewCalculator(config)
+	calculator.now = time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	results, err := calculator.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	first := results.AnnualProjections[0]
+	if first.Age != 64 || first.Year != 2031 {
+		t.Errorf("Expected the projection to start this year at age 64 (2031), got age %d (%d)", first.Age, first.Year)
+	}
+	if first.TSPStartBalance != config.TSP.TraditionalBalance+config.TSP.RothBalance {
+		t.Errorf("Expected the entered balances to be treated as current, got %.2f", first.TSPStartBalance)
+	}
+	for i := 1; i < len(results.AnnualProjections); i++ {
+		if results.AnnualProjections[i].Year != results.AnnualProjections[i-1].Year+1 {
+			t.Fatalf("Expected consecutive projection years, got %d after %d",
+				results.AnnualProjections[i].Year, results.AnnualProjections[i-1].Year)
+		}
+	}
+
+	// The pension carries the COLAs granted since retirement
+	future := NewCalculator(config)
+	future.now = time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)
+	fromRetirement, err := future.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if math.Abs(first.PensionIncome-fromRetirement.AnnualProjections[2].PensionIncome) > 0.01 {
+		t.Errorf("Expected the age 64 pension %.2f to match the projection from retirement, got %.2f",
+			fromRetirement.AnnualProjections[2].PensionIncome, first.PensionIncome)
+	}
+	if containsWarning(fromRetirement.Metadata.Warnings, "in the past") {
+		t.Error("Did not expect a past-date warning for a future retirement")
+	}
+	if !containsWarning(results.Metadata.Warnings, "projecting as already retired") {
+		t.Errorf("Expected a past retirement date warning, got %v", results.Metadata.Warnings)
+	}
+}
+
+func TestTaxableSSShareRisesWithIndexedBrackets(t *testing.T) {
+	config := createTestConfig()
+	config.TaxInfo.IndexBrackets = true
+	config.Assumptions.InflationRate = models.Rate(0.025)
+	config.Assumptions.COLARate = models.Rate(0.025)
+
+	calculator := NewCalculator(config)
+	results, err := calculator.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Income keeps pace with inflation, but the $25,000/$34,000 thresholds do not
+	taxableShare := func(age int) float64 {
+		for _, p := range results.AnnualProjections {
+			if p.Age == age {
+				return calculateTaxableSS(p.SocialSecurityIncome, p.GrossIncome, singleSchedule) / p.SocialSecurityIncome
+			}
+		}
+		t.Fatalf("No projection at age %d", age)
+		return 0
+	}
+	if first, later := taxableShare(67), taxableShare(87); later <= first {
+		t.Errorf("Expected the taxable share of Social Security to rise from %.1f%% over 20 years, got %.1f%%",
+			first*100, later*100)
+	}
+
+	// The standard deduction grows with inflation, the thresholds stay fixed
+	if factor := calculator.taxIndexFactor(87); math.Abs(factor-math.Pow(1.025, 2054-2025)) > 1e-9 {
+		t.Errorf("Expected the bracket index factor to compound from 2025, got %.4f", factor)
+	}
+	// Provisional income of $35,000 is $1,000 over the fixed $34,000 threshold
+	if taxable := calculateTaxableSS(30000, 50000, singleSchedule); math.Abs(taxable-(4500+1000*0.85)) > 0.01 {
+		t.Error("Expected Social Security taxation thresholds to ignore bracket indexing")
+	}
+}
+
+// containsWarning reports whether any warning contains the given text
+func containsWarning(warnings []string, text string) bool {
+	for _, w := range warnings {
+		if strings.Contains(w, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFEHBCurrentPremiumCarriesIntoRetirement(t *testing.T) {
+	config := createTestConfig()
+	config.HealthInsurance.CurrentPremium = 3600
+	calc := NewCalculator(config)
+
+	if premium := calc.calculateHealthInsurance(calc.calculateAgeAtRetirement()); premium != 3600 {
+		t.Errorf("Expected active premium 3600 to carry into retirement, got %.2f", premium)
+	}
+
+	if containsWarning(calc.generateWarnings(), "FEHB premium") {
+		t.Error("Did not expect FEHB premium warning when only the active premium is set")
+	}
+}
+
+func TestFEHBPremiumDiscrepancyWarning(t *testing.T) {
+	config := createTestConfig()
+	config.HealthInsurance.CurrentPremium = 3600
+	config.HealthInsurance.RetirementPremium = 9000
+
+	if !containsWarning(NewCalculator(config).generateWarnings(), "FEHB premium") {
+		t.Error("Expected FEHB premium discrepancy warning")
+	}
+}
+
+func TestSocialSecurityMaximumBenefitCap(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.EstimatedPIA = 9000 // Far above the statutory maximum
+	calc := NewCalculator(config)
+
+	ss := calc.calculateSocialSecurity()
+	if !ss.Capped {
+		t.Error("Expected benefit to be capped")
+	}
+	if ss.MonthlyBenefit != maxSSPIA {
+		t.Errorf("Expected benefit capped to %.2f at FRA, got %.2f", maxSSPIA, ss.MonthlyBenefit)
+	}
+
+	if !containsWarning(calc.generateWarnings(), "statutory maximum") {
+		t.Error("Expected warning when benefit is capped")
+	}
+
+	// A realistic PIA is left alone
+	config.SocialSecurity.EstimatedPIA = 2800
+	if ss := calc.calculateSocialSecurity(); ss.Capped || ss.MonthlyBenefit != 2800 {
+		t.Errorf("Expected uncapped benefit 2800, got %.2f (capped=%v)", ss.MonthlyBenefit, ss.Capped)
+	}
+}
+
+func TestSurvivorBreakEvenAge(t *testing.T) {
+	config := createTestConfig()
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	summary := results.Summary
+	base := 25.0 * 82000.0 * 0.011
+
+	// Full FERS election: 10% cost buys a 50% survivor annuity
+	if diff := summary.SurvivorAnnuity - base*0.5; diff > 0.01 || diff < -0.01 {
+		t.Errorf("Expected survivor annuity %.2f, got %.2f", base*0.5, summary.SurvivorAnnuity)
+	}
+
+	// cost*(D-62) = annuity*(95-D) with cost=0.1 and annuity=0.5 of the base
+	expected := (0.1*62 + 0.5*95) / 0.6
+	if diff := summary.SurvivorBreakEvenAge - expected; diff > 0.01 || diff < -0.01 {
+		t.Errorf("Expected break-even age %.2f, got %.2f", expected, summary.SurvivorBreakEvenAge)
+	}
+
+	config.Retirement.SurvivorBenefit = "none"
+	results, err = NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.SurvivorBreakEvenAge != 0 {
+		t.Errorf("Expected no break-even age without an election, got %.2f", results.Summary.SurvivorBreakEvenAge)
+	}
+}
+
+func TestParallelScenarioSweepMatchesSerial(t *testing.T) {
+	var configs []*models.Config
+	for i := 0; i < 50; i++ {
+		config := createTestConfig()
+		config.TSP.GrowthRate = models.Rate(0.03 + float64(i)*0.001)
+		config.Retirement.TargetRetirementDate = time.Date(2024+i%8, 3, 15, 0, 0, 0, 0, time.UTC)
+		configs = append(configs, config)
+	}
+
+	serial, err := calculateScenariosWithWorkers(configs, 1)
+	if err != nil {
+		t.Fatalf("Serial sweep failed: %v", err)
+	}
+	parallel, err := calculateScenariosWithWorkers(configs, 8)
+	if err != nil {
+		t.Fatalf("Parallel sweep failed: %v", err)
+	}
+
+	if len(serial) != len(configs) || len(parallel) != len(configs) {
+		t.Fatalf("Expected %d results, got serial=%d parallel=%d", len(configs), len(serial), len(parallel))
+	}
+
+	for i := range serial {
+		if !reflect.DeepEqual(serial[i].Summary, parallel[i].Summary) {
+			t.Errorf("Scenario %d summary differs between serial and parallel runs", i)
+		}
+		if !reflect.DeepEqual(serial[i].AnnualProjections, parallel[i].AnnualProjections) {
+			t.Errorf("Scenario %d projections differ between serial and parallel runs", i)
+		}
+	}
+}
+
+func TestPhasedRetirement(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.PhasedRetirement = &models.PhasedRetirementInfo{
+		Years:          2,
+		PartTimeSalary: 41000,
+	}
+
+	calc := NewCalculator(config)
+	pension, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, proj := range results.AnnualProjections[:2] {
+		if proj.PensionIncome != pension.FinalPension*0.5 {
+			t.Errorf("Phased year %d: expected partial annuity %.2f, got %.2f", i, pension.FinalPension*0.5, proj.PensionIncome)
+		}
+		if proj.SalaryIncome != 41000 {
+			t.Errorf("Phased year %d: expected salary 41000, got %.2f", i, proj.SalaryIncome)
+		}
+	}
+
+	fullRetirement := results.AnnualProjections[2]
+	if fullRetirement.SalaryIncome != 0 {
+		t.Errorf("Expected no salary after phased retirement, got %.2f", fullRetirement.SalaryIncome)
+	}
+	if fullRetirement.PensionIncome <= pension.FinalPension {
+		t.Errorf("Expected composite annuity above the immediate annuity %.2f, got %.2f", pension.FinalPension, fullRetirement.PensionIncome)
+	}
+}
+
+func TestTSPWithdrawalCeiling(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "percentage"
+	config.TSP.WithdrawalRate = 0.10
+	config.TSP.WithdrawalCeiling = 30000
+
+	if withdrawal := NewCalculator(config).calculateTSPWithdrawal(500000, 400000, 62); withdrawal != 30000 {
+		t.Errorf("Expected ceiling to clamp withdrawal to 30000, got %.2f", withdrawal)
+	}
+}
+
+func TestTSPWithdrawalRMDFloor(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "percentage"
+	config.TSP.WithdrawalRate = 0.01
+	config.TSP.WithdrawalCeiling = 10000
+	config.TSP.RothBalance = 0 // Roth balances are exempt from RMDs
+	calc := NewCalculator(config)
+
+	// Before RMDs begin the small percentage withdrawal stands
+	if withdrawal := calc.calculateTSPWithdrawal(500000, 500000, 70); withdrawal != 5000 {
+		t.Errorf("Expected 1%% withdrawal of 5000 before RMD age, got %.2f", withdrawal)
+	}
+
+	// At 80 the RMD raises the withdrawal, even above the ceiling
+	expected := 500000 / 20.2
+	if withdrawal := calc.calculateTSPWithdrawal(500000, 500000, 80); withdrawal < expected-0.01 || withdrawal > expected+0.01 {
+		t.Errorf("Expected RMD floor of %.2f at age 80, got %.2f", expected, withdrawal)
+	}
+
+	// Without a guardrail the percentage strategy withdraws exactly its own amount
+	config.TSP.WithdrawalCeiling = 0
+	if withdrawal := NewCalculator(config).calculateTSPWithdrawal(500000, 500000, 80); withdrawal != 5000 {
+		t.Errorf("Expected the 1%% withdrawal of 5000 at age 80 without guardrails, got %.2f", withdrawal)
+	}
+}
+
+func TestDeferredAnnuityUsesOnePercentMultiplier(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.TargetRetirementDate = time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC) // Separate at 55
+	config.Retirement.DeferredStartAge = 62
+	calc := NewCalculator(config)
+
+	pension, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	// Commencing at 62 after separating at 55 still uses the 1.0% multiplier, with no age reduction
+	expectedBase := 25.0 * 82000.0 * 0.01
+	if pension.BasePension != expectedBase {
+		t.Errorf("Expected deferred base pension %.2f (1.0%%), got %.2f", expectedBase, pension.BasePension)
+	}
+	if pension.ReductionPercent != 0 {
+		t.Errorf("Expected no reduction for a deferred annuity commencing at 62, got %.1f%%", pension.ReductionPercent)
+	}
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, proj := range results.AnnualProjections {
+		if proj.Age < 62 && proj.PensionIncome != 0 {
+			t.Errorf("Expected no pension before the deferred start age, got %.2f at age %d", proj.PensionIncome, proj.Age)
+		}
+		if proj.Age == 62 && proj.PensionIncome != pension.FinalPension {
+			t.Errorf("Expected pension %.2f to begin at 62, got %.2f", pension.FinalPension, proj.PensionIncome)
+		}
+	}
+}
+
+func TestCustomStateTaxRule(t *testing.T) {
+	config := createTestConfig()
+	config.TaxInfo.State = "VA"
+	config.TaxInfo.CustomStateTax = &models.CustomStateTax{
+		Brackets: []models.StateTaxBracket{
+			{Over: 0, Rate: 0.03},
+			{Over: 20000, Rate: 0.05},
+		},
+		PensionExempt:  true,
+		Age65Exclusion: 10000,
+	}
+	calc := NewCalculator(config)
+
+	projection := models.AnnualProjection{PensionIncome: 30000, TSPWithdrawal: 40000, GrossIncome: 70000}
+
+	// 70000 gross - 30000 pension - 10000 age-65 exclusion = 30000 taxable:
+	// 20000 at 3% plus 10000 at 5%
+	if tax := calc.calculateStateTax(projection, 66); math.Abs(tax-1100) > 0.01 {
+		t.Errorf("Expected custom state tax of 1100, got %.2f", tax)
+	}
+	// Before 65 the exclusion does not apply: 20000 at 3% plus 20000 at 5%
+	if tax := calc.calculateStateTax(projection, 64); math.Abs(tax-1600) > 0.01 {
+		t.Errorf("Expected custom state tax of 1600 before 65, got %.2f", tax)
+	}
+}
+
+func TestResidenceChangeToNoTaxState(t *testing.T) {
+	config := createTestConfig()
+	config.TaxInfo.State = "VA"
+	config.TaxInfo.StateTaxRate = 0.05
+	config.TaxInfo.ResidenceChanges = []models.ResidenceChange{
+		{Age: 70, State: "FL"},
+	}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for _, proj := range results.AnnualProjections {
+		if proj.Age < 70 && proj.StateTax <= 0 {
+			t.Errorf("Expected VA state tax at age %d, got %.2f", proj.Age, proj.StateTax)
+		}
+		if proj.Age >= 70 && proj.StateTax != 0 {
+			t.Errorf("Expected no state tax after moving to FL at age %d, got %.2f", proj.Age, proj.StateTax)
+		}
+	}
+}
+
+func TestMidYearMoveApportionsStateTax(t *testing.T) {
+	config := createTestConfig()
+	config.TaxInfo.State = "VA"
+	config.TaxInfo.StateTaxRate = 0.05
+
+	stayed, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Move to FL on July 1 of the year at age 70
+	config.TaxInfo.ResidenceChanges = []models.ResidenceChange{
+		{Age: 70, Month: 7, State: "FL"},
+	}
+	moved, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, proj := range moved.AnnualProjections {
+		var expected float64
+		switch {
+		case proj.Age < 70:
+			expected = stayed.AnnualProjections[i].StateTax
+		case proj.Age == 70:
+			expected = stayed.AnnualProjections[i].StateTax / 2 // VA taxes January through June
+		}
+		if math.Abs(proj.StateTax-expected) > 0.01 {
+			t.Errorf("Age %d: expected state tax %.2f, got %.2f", proj.Age, expected, proj.StateTax)
+		}
+	}
+}
+
+func TestFindEligibilityMilestones(t *testing.T) {
+	config := createTestConfig()
+	report, err := NewCalculator(config).FindEligibilityMilestones()
+	if err != nil {
+		t.Fatalf("FindEligibilityMilestones failed: %v", err)
+	}
+
+	dates := make(map[string]time.Time)
+	for _, m := range report.Milestones {
+		dates[m.Name] = m.Date
+	}
+
+	// Born 1967: MRA is 56 and 6 months (2023-09-15); 30 years of service on 2029-01-15
+	expected := map[string]time.Time{
+		"MRA+30":              time.Date(2029, 1, 15, 0, 0, 0, 0, time.UTC),
+		"Age 62 with 5 years": time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC),
+		"MRA+10 (reduced)":    time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for name, want := range expected {
+		got, ok := dates[name]
+		if !ok {
+			t.Errorf("Missing milestone %q", name)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("Milestone %q: expected %s, got %s", name, want.Format("2006-01-02"), got.Format("2006-01-02"))
+		}
+	}
+
+	for i := 1; i < len(report.Milestones); i++ {
+		if report.Milestones[i].Date.Before(report.Milestones[i-1].Date) {
+			t.Errorf("Milestones not in date order at index %d", i)
+		}
+	}
+}
+
+func TestEligibilityCountsRedepositedService(t *testing.T) {
+	// Born 1967: at the MRA of 56 and 6 months with 8 years, short of MRA+10
+	config := createTestConfig()
+	config.Employment.HireDate = time.Date(2015, 9, 15, 0, 0, 0, 0, time.UTC)
+	config.Retirement.TargetRetirementDate = time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC)
+	config.Employment.CreditableService.TotalYears = 8
+	config.Employment.CreditableService.RefundedService = []models.RefundedPeriod{
+		{
+			StartDate: time.Date(1992, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	if NewCalculator(config).CheckRetirementEligibility() {
+		t.Error("Expected refunded service without a redeposit not to count toward eligibility")
+	}
+
+	config.Employment.CreditableService.RefundedService[0].RedepositPaid = true
+	if !NewCalculator(config).CheckRetirementEligibility() {
+		t.Error("Expected redeposited service to make the retiree MRA+10 eligible")
+	}
+
+	report, err := NewCalculator(config).FindEligibilityMilestones()
+	if err != nil {
+		t.Fatalf("FindEligibilityMilestones failed: %v", err)
+	}
+	for _, m := range report.Milestones {
+		// 10 years is 7 years after the 2015 hire date plus the 3 redeposited years
+		if m.Name == "MRA+10 (reduced)" && !m.Date.Equal(time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected MRA+10 at the MRA with redeposited service, got %s", m.Date.Format("2006-01-02"))
+		}
+		if m.Name == "Age 60 with 20 years" && !m.Date.Equal(time.Date(2032, 9, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected 20 years on 2032-09-15 with redeposited service, got %s", m.Date.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestProportionalWithdrawalTaxesTraditionalShare(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalSource = "proportional" // 400k Traditional / 100k Roth
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	firstYear := results.AnnualProjections[0]
+	expected := firstYear.TSPWithdrawal * 0.8
+	if diff := firstYear.TaxableTSPWithdrawal - expected; diff < -0.01 || diff > 0.01 {
+		t.Errorf("Expected taxable withdrawal %.2f (80%% of %.2f), got %.2f",
+			expected, firstYear.TSPWithdrawal, firstYear.TaxableTSPWithdrawal)
+	}
+
+	config.TSP.WithdrawalSource = ""
+	traditional, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if traditional.AnnualProjections[0].TaxableTSPWithdrawal != traditional.AnnualProjections[0].TSPWithdrawal {
+		t.Error("Expected the default source to tax the entire withdrawal")
+	}
+	if firstYear.FederalTax >= traditional.AnnualProjections[0].FederalTax {
+		t.Errorf("Expected proportional withdrawals to lower federal tax: %.2f vs %.2f",
+			firstYear.FederalTax, traditional.AnnualProjections[0].FederalTax)
+	}
+}
+
+func TestPostponedMRA10HasNoSupplement(t *testing.T) {
+	// Separating at the MRA with 20 years and starting the annuity at 60 reaches the
+	// age 60 + 20 supplement threshold by commencement, but a postponed annuity never pays it
+	config := createTestConfig()
+	config.Personal.BirthDate = time.Date(1970, 6, 1, 0, 0, 0, 0, time.UTC)
+	config.Employment.HireDate = time.Date(2007, 6, 1, 0, 0, 0, 0, time.UTC)
+	config.Retirement.TargetRetirementDate = time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC) // Age 57, the MRA
+	config.Employment.CreditableService.TotalYears = 20
+	config.Retirement.EarlyRetirement = &models.EarlyRetirementInfo{
+		Type:           "MRA+10",
+		PostponedStart: true,
+	}
+	config.Retirement.DeferredStartAge = 60
+	calc := NewCalculator(config)
+
+	if fersup := calc.calculateFERSSupplement(); fersup.Eligible {
+		t.Errorf("Expected no FERS supplement for a postponed MRA+10 retirement, got $%.2f/month", fersup.MonthlyAmount)
+	}
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	checked := 0
+	for _, p := range results.AnnualProjections {
+		if p.Age < 60 || p.Age >= 62 {
+			continue
+		}
+		checked++
+		if p.PensionIncome <= 0 {
+			t.Errorf("Expected the postponed annuity to be paid at age %d", p.Age)
+		}
+		if p.FERSSupplementIncome != 0 {
+			t.Errorf("Expected no FERS supplement at age %d, got %.2f", p.Age, p.FERSSupplementIncome)
+		}
+	}
+	if checked != 2 {
+		t.Fatalf("Expected projections for ages 60 and 61, got %d", checked)
+	}
+
+	if !containsWarning(calc.generateWarnings(), "No FERS annuity supplement") {
+		t.Error("Expected a warning explaining why the supplement is not payable")
+	}
+}
+
+func TestFERSSupplementOverrideAndDisable(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.TargetRetirementDate = time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC) // Age 60
+	config.Employment.CreditableService.TotalYears = 28
+	config.Retirement.SupplementOverride = 1234
+
+	fersup := NewCalculator(config).calculateFERSSupplement()
+	if !fersup.Eligible || fersup.MonthlyAmount != 1234 {
+		t.Errorf("Expected override supplement of $1234/month, got eligible=%v $%.2f", fersup.Eligible, fersup.MonthlyAmount)
+	}
+
+	config.Retirement.DisableSupplement = true
+	fersup = NewCalculator(config).calculateFERSSupplement()
+	if fersup.Eligible || fersup.MonthlyAmount != 0 {
+		t.Errorf("Expected no supplement when disabled, got eligible=%v $%.2f", fersup.Eligible, fersup.MonthlyAmount)
+	}
+}
+
+func TestFERSSupplementSSBase(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.TargetRetirementDate = time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC) // Age 60
+	config.Employment.CreditableService.TotalYears = 28
+
+	fromPIA := NewCalculator(config).calculateFERSSupplement()
+	config.Retirement.SupplementSSBase = 1600
+	fromBase := NewCalculator(config).calculateFERSSupplement()
+
+	if expected := 1600.0 / 40 * 28; math.Abs(fromBase.MonthlyAmount-expected) > 0.01 {
+		t.Errorf("Expected a supplement of $%.2f from the FERS-only base, got $%.2f", expected, fromBase.MonthlyAmount)
+	}
+	if fromBase.MonthlyAmount >= fromPIA.MonthlyAmount {
+		t.Errorf("Expected a lower FERS-only base to lower the supplement below $%.2f, got $%.2f",
+			fromPIA.MonthlyAmount, fromBase.MonthlyAmount)
+	}
+
+	// The main Social Security benefit still uses the PIA
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.MonthlySocialSecurity != config.SocialSecurity.EstimatedPIA {
+		t.Errorf("Expected Social Security from the $%.2f PIA, got $%.2f",
+			config.SocialSecurity.EstimatedPIA, results.Summary.MonthlySocialSecurity)
+	}
+}
+
+func TestTaxCliffFlaggedWhenSSAndRMDBegin(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.High3Salary = 150000
+	config.TSP.TraditionalBalance = 2000000
+	config.TSP.RothBalance = 0
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 5000
+	config.TSP.WithdrawalCeiling = 100000 // Guardrails turn on the RMD floor
+	config.SocialSecurity.ClaimingAge = 70
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Born 1967: Social Security begins at 70 and RMDs at 75
+	flagged := make(map[int]bool)
+	for _, proj := range results.AnnualProjections {
+		flagged[proj.Age] = proj.BracketCrossing
+	}
+	for _, age := range []int{70, 75} {
+		if !flagged[age] {
+			t.Errorf("Expected age %d to be flagged as crossing into a higher bracket", age)
+		}
+	}
+	if flagged[62] {
+		t.Error("The first projection year has no prior year and should not be flagged")
+	}
+
+	if results.Summary.FirstTaxCliffAge != 70 {
+		t.Errorf("Expected first tax cliff at age 70, got %d", results.Summary.FirstTaxCliffAge)
+	}
+}
+
+func TestIncomeGapBetweenSupplementAndSS(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.TargetRetirementDate = time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC) // Age 60
+	config.Employment.CreditableService.TotalYears = 28
+	config.SocialSecurity.ClaimingAge = 65
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for _, proj := range results.AnnualProjections {
+		wantGap := proj.Age >= 62 && proj.Age < 65
+		if proj.IncomeGap != wantGap {
+			t.Errorf("Age %d: expected income gap %v, got %v", proj.Age, wantGap, proj.IncomeGap)
+		}
+	}
+
+	summary := results.Summary
+	if summary.IncomeGapStartAge != 62 || summary.IncomeGapYears != 3 {
+		t.Errorf("Expected a 3-year gap starting at 62, got %d years at %d", summary.IncomeGapYears, summary.IncomeGapStartAge)
+	}
+	if summary.IncomeGapDip <= 0 {
+		t.Errorf("Expected a positive income dip entering the gap, got %.2f", summary.IncomeGapDip)
+	}
+}
+
+func TestNoIncomeGapWithoutSocialSecurity(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.RetirementSystem = "CSRS"
+	config.Employment.CreditableService.TotalYears = 30
+	config.SocialSecurity.EstimatedPIA = 0
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, proj := range results.AnnualProjections {
+		if proj.IncomeGap {
+			t.Fatalf("Expected no income gap without a Social Security benefit, got one at age %d", proj.Age)
+		}
+	}
+	if results.Summary.IncomeGapYears != 0 {
+		t.Errorf("Expected no income gap in the summary, got %d years", results.Summary.IncomeGapYears)
+	}
+}
+
+func TestOneTimeExpenseShortfall(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.High3Salary = 60000
+	config.TSP.TraditionalBalance = 0
+	config.TSP.RothBalance = 0
+	config.SocialSecurity.EstimatedPIA = 1000
+	config.Spending.OneTimeExpenses = []models.OneTimeExpense{
+		{Age: 70, Amount: 40000, Description: "New roof"},
+	}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for _, proj := range results.AnnualProjections {
+		if proj.Age != 70 {
+			if proj.Shortfall != 0 {
+				t.Errorf("Expected no shortfall at age %d, got %.2f", proj.Age, proj.Shortfall)
+			}
+			continue
+		}
+		if proj.NetIncome >= 40000 {
+			t.Fatalf("Test setup expects net income below $40k at 70, got %.2f", proj.NetIncome)
+		}
+		expected := 40000 - proj.NetIncome
+		if diff := proj.Shortfall - expected; diff < -0.01 || diff > 0.01 {
+			t.Errorf("Expected shortfall %.2f at age 70, got %.2f", expected, proj.Shortfall)
+		}
+	}
+
+	if results.Summary.FirstShortfallAge != 70 || results.Summary.ShortfallYears != 1 {
+		t.Errorf("Expected a single shortfall year at 70, got %d years starting %d",
+			results.Summary.ShortfallYears, results.Summary.FirstShortfallAge)
+	}
+}
+
+func TestIneligibleServiceIsSentinel(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 4
+
+	_, err := NewCalculator(config).Calculate()
+	if !errors.Is(err, ErrIneligible) {
+		t.Errorf("Expected errors.Is(err, ErrIneligible) with under 5 years of service, got %v", err)
+	}
+}
+
+func TestTransfereeAnnuityIsSumOfComponents(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 30
+	config.Employment.CreditableService.Transfer = &models.TransferService{
+		CSRSYears: 10,
+		FERSYears: 20,
+	}
+	calc := NewCalculator(config)
+
+	pension, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	// CSRS: 5 * 1.5% + 5 * 1.75% of high-3; FERS: 20 * 1.1% (age 62 with 30 total years)
+	csrsComponent := 82000 * (5*0.015 + 5*0.0175)
+	fersComponent := 82000 * 0.011 * 20
+	expected := csrsComponent + fersComponent
+	if diff := pension.BasePension - expected; diff < -0.01 || diff > 0.01 {
+		t.Errorf("Expected transferee annuity %.2f (CSRS %.2f + FERS %.2f), got %.2f",
+			expected, csrsComponent, fersComponent, pension.BasePension)
+	}
+}
+
+func TestSpecialProvisionTransfereeUsesSpecialFormulas(t *testing.T) {
+	// A LEO retiring at 52 with 8 CSRS and 17 FERS years qualifies on total service only
+	config := createTestConfig()
+	config.Personal.SpecialProvision = true
+	config.Personal.BirthDate = time.Date(1977, 3, 15, 0, 0, 0, 0, time.UTC)
+	config.Employment.CreditableService.TotalYears = 25
+	config.Employment.CreditableService.Transfer = &models.TransferService{
+		CSRSYears: 8,
+		FERSYears: 17,
+	}
+	calc := NewCalculator(config)
+
+	pension, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	// CSRS: 8 * 2.5%; FERS: the 12 years left of the first 20 at 1.7%, then 5 at 1%
+	csrsComponent := 82000 * 8 * 0.025
+	fersComponent := 82000 * (12*0.017 + 5*0.01)
+	if expected := csrsComponent + fersComponent; math.Abs(pension.BasePension-expected) > 0.01 {
+		t.Errorf("Expected transferee annuity %.2f (CSRS %.2f + FERS %.2f), got %.2f",
+			expected, csrsComponent, fersComponent, pension.BasePension)
+	}
+	if pension.ReductionPercent != 0 {
+		t.Errorf("Expected no reduction for a special provision retirement, got %.1f%%", pension.ReductionPercent)
+	}
+}
+
+func TestTransfereeAnnuityBlendsComponentCOLAs(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 30
+	config.Employment.CreditableService.Transfer = &models.TransferService{
+		CSRSYears: 10,
+		FERSYears: 20,
+	}
+	config.Retirement.SurvivorBenefit = "none"
+	config.Assumptions.COLARate = models.Rate(0.03)
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Retiring at 62, the CSRS component gets the full 3% and the FERS component the 2% diet
+	// COLA, so over 10 years the annuity grows by the share-weighted blend of the two
+	csrsComponent := 82000 * (5*0.015 + 5*0.0175)
+	fersComponent := 82000 * 0.011 * 20
+	csrsShare := csrsComponent / (csrsComponent + fersComponent)
+	expected := csrsShare*math.Pow(1.03, 10) + (1-csrsShare)*math.Pow(1.02, 10)
+
+	start, later := results.AnnualProjections[0], results.AnnualProjections[10]
+	if later.Age != 72 {
+		t.Fatalf("Expected age 72 ten years in, got %d", later.Age)
+	}
+	if growth := later.PensionIncome / start.PensionIncome; math.Abs(growth-expected) > 1e-9 {
+		t.Errorf("Expected 10-year annuity growth of %.6f from the blended COLAs, got %.6f", expected, growth)
+	}
+}
+
+func TestDataQualityFlagsSimplifiedSS(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.MonthlyEstimates = nil
+
+	notes := NewCalculator(config).dataQualityNotes()
+	if !containsWarning(notes, "Social Security is estimated") {
+		t.Errorf("Expected Social Security to be flagged as estimated, got %v", notes)
+	}
+
+	config.SocialSecurity.MonthlyEstimates = map[int]float64{67: 2800}
+	notes = NewCalculator(config).dataQualityNotes()
+	if containsWarning(notes, "Social Security") {
+		t.Errorf("Expected no Social Security note with a statement estimate, got %v", notes)
+	}
+}
+
+func TestBequestWithdrawalLeavesTarget(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "bequest"
+	// Small enough that required minimum distributions never exceed the level withdrawal
+	config.Retirement.BequestTarget = 50000
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	projections := results.AnnualProjections
+	final := projections[len(projections)-1]
+	years := float64(final.Age - projections[0].Age + 1)
+	expected := 50000 * math.Pow(1+calc.inflationRate(), years)
+
+	if math.Abs(final.TSPEndBalance-expected)/expected > 0.01 {
+		t.Errorf("Expected ending balance near the inflated target %.2f, got %.2f", expected, final.TSPEndBalance)
+	}
+}
+
+func TestIncomeOnlyWithdrawalKeepsBalanceFlat(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "income_only"
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	start := config.TSP.TraditionalBalance + config.TSP.RothBalance
+	for _, p := range results.AnnualProjections {
+		if p.Age >= calc.rmdStartAge() {
+			break
+		}
+		if math.Abs(p.TSPEndBalance-start) > 0.01 {
+			t.Fatalf("Expected balance to stay at %.2f before RMDs, got %.2f at age %d", start, p.TSPEndBalance, p.Age)
+		}
+		if math.Abs(p.TSPWithdrawal-p.TSPGrowth) > 0.01 {
+			t.Errorf("Expected withdrawal to equal growth at age %d, got %.2f vs %.2f", p.Age, p.TSPWithdrawal, p.TSPGrowth)
+		}
+	}
+}
+
+func TestIncomeOnlyWithdrawsPriorYearReturn(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "income_only"
+	config.TSP.ReturnSequence = []float64{-0.20, 0.10}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// The first year uses the growth rate, later years the return realized the year before
+	first, second, third := results.AnnualProjections[0], results.AnnualProjections[1], results.AnnualProjections[2]
+	if expected := first.TSPStartBalance * 0.07; math.Abs(first.TSPWithdrawal-expected) > 0.01 {
+		t.Errorf("Expected a first-year withdrawal of %.2f at the growth rate, got %.2f", expected, first.TSPWithdrawal)
+	}
+	if second.TSPWithdrawal != 0 {
+		t.Errorf("Expected no withdrawal after a 20%% loss, got %.2f", second.TSPWithdrawal)
+	}
+	if expected := third.TSPStartBalance * 0.10; math.Abs(third.TSPWithdrawal-expected) > 0.01 {
+		t.Errorf("Expected a withdrawal of %.2f from the prior year's 10%% return, got %.2f", expected, third.TSPWithdrawal)
+	}
+}
+
+func TestMaxAnnualGrowthCapsCompounding(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.GrowthRate = models.Rate(0.07)
+
+	uncapped, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	config.TSP.MaxAnnualGrowth = 0.06
+	capped, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if rate := capped.AnnualProjections[0].TSPReturn; rate != 0.06 {
+		t.Errorf("Expected growth capped at 6%%, got %.4f", rate)
+	}
+	n := len(capped.AnnualProjections) - 1
+	if capped.AnnualProjections[n].TSPEndBalance >= uncapped.AnnualProjections[n].TSPEndBalance {
+		t.Errorf("Expected the 6%% cap to lower the ending balance below $%.2f, got $%.2f",
+			uncapped.AnnualProjections[n].TSPEndBalance, capped.AnnualProjections[n].TSPEndBalance)
+	}
+}
+
+func TestCashReserveSpentBeforeTSPInDownYear(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.ReturnSequence = []float64{-0.20, 0.10}
+	config.CashReserve.Balance = 50000
+	calc := NewCalculator(config)
+
+	projections, err := calc.generateAnnualProjections(models.PensionCalculation{}, models.SocialSecurityCalculation{ClaimingAge: 67}, models.FERSSupplementCalculation{})
+	if err != nil {
+		t.Fatalf("generateAnnualProjections failed: %v", err)
+	}
+
+	down := projections[0]
+	if down.CashWithdrawal <= 0 || down.TSPWithdrawal != 0 {
+		t.Errorf("Expected the down year to draw on cash only, got cash %.2f and TSP %.2f", down.CashWithdrawal, down.TSPWithdrawal)
+	}
+	if expected := down.TSPStartBalance * 0.80; math.Abs(down.TSPEndBalance-expected) > 0.01 {
+		t.Errorf("Expected TSP to fall only by the market loss to %.2f, got %.2f", expected, down.TSPEndBalance)
+	}
+
+	up := projections[1]
+	if up.CashWithdrawal != 0 || math.Abs(up.CashRefill-down.CashWithdrawal) > 0.01 || math.Abs(up.CashEndBalance-50000) > 0.01 {
+		t.Errorf("Expected the good year to refill cash by %.2f to 50000, got refill %.2f and balance %.2f",
+			down.CashWithdrawal, up.CashRefill, up.CashEndBalance)
+	}
+}
+
+func TestCashReserveRefillsAreTaxedOnce(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 30000
+	config.TSP.TaxTreatment = "all_traditional"
+	config.TSP.ReturnSequence = []float64{-0.05, 0.05}
+	config.TaxInfo.StateTaxRate = 0.05
+
+	without, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.CashReserve = models.CashReserveInfo{Balance: 50000, InterestRate: 0.02}
+	calc := NewCalculator(config)
+	with, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Until RMDs begin the same Traditional money leaves the TSP either way, except that the
+	// interest earned while the reserve was drawn down shrinks the refill
+	var taxedWithout, taxedWith, stateTaxWithout, stateTaxWith float64
+	for i, p := range with.AnnualProjections {
+		if p.Age >= calc.rmdStartAge() {
+			break
+		}
+		base := without.AnnualProjections[i]
+		taxedWithout += base.TaxableTSPWithdrawal
+		taxedWith += p.TaxableTSPWithdrawal + p.TaxableCashRefill
+		stateTaxWithout += base.StateTax
+		stateTaxWith += p.StateTax
+	}
+	// The drawn-down reserve earns two years of interest before the refill tops it up
+	down := with.AnnualProjections[0]
+	interest := (50000 - down.CashWithdrawal) * (math.Pow(1.02, 2) - 1)
+	if down.CashWithdrawal <= 0 || with.AnnualProjections[1].TaxableCashRefill <= 0 {
+		t.Fatalf("Expected the reserve to be spent in the down year and refilled the next")
+	}
+	if math.Abs(taxedWithout-taxedWith-interest) > 0.01 {
+		t.Errorf("Expected taxed TSP money to differ by the $%.2f of interest, got %.2f with and %.2f without",
+			interest, taxedWith, taxedWithout)
+	}
+	if math.Abs(stateTaxWithout-stateTaxWith-interest*0.05) > 0.01 {
+		t.Errorf("Expected the flat state tax to differ by the tax on $%.2f of interest, got %.2f with and %.2f without",
+			interest, stateTaxWith, stateTaxWithout)
+	}
+}
+
+func TestImplausibleTSPBalanceWarns(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.TraditionalBalance = 10000000
+
+	if !containsWarning(NewCalculator(config).generateWarnings(), "plausibility limit") {
+		t.Error("Expected a plausibility warning for a $10M TSP balance")
+	}
+
+	config.TSP.PlausibleBalanceLimit = 20000000
+	if containsWarning(NewCalculator(config).generateWarnings(), "plausibility limit") {
+		t.Error("Expected no warning when the balance is under a configured limit")
+	}
+}
+
+func TestOtherPensionIncludedFromStartAge(t *testing.T) {
+	config := createTestConfig()
+	config.OtherPensions = []models.OtherPension{{Name: "Prior employer", AnnualAmount: 6000, StartAge: 65, COLA: 0.02}}
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for _, p := range results.AnnualProjections {
+		switch {
+		case p.Age < 65 && p.OtherPensionIncome != 0:
+			t.Errorf("Expected no other pension before age 65, got %.2f at age %d", p.OtherPensionIncome, p.Age)
+		case p.Age == 65 && p.OtherPensionIncome != 6000:
+			t.Errorf("Expected $6000 other pension at age 65, got %.2f", p.OtherPensionIncome)
+		case p.Age == 66 && math.Abs(p.OtherPensionIncome-6120) > 0.01:
+			t.Errorf("Expected COLA-adjusted $6120 at age 66, got %.2f", p.OtherPensionIncome)
+		}
+
+		sum := p.PensionIncome + p.FERSSupplementIncome + p.SocialSecurityIncome + p.TSPWithdrawal +
+			p.CashWithdrawal + p.SalaryIncome + p.OtherPensionIncome
+		if math.Abs(p.GrossIncome-sum) > 0.01 {
+			t.Fatalf("Expected other pension in gross income at age %d: gross %.2f, sources %.2f", p.Age, p.GrossIncome, sum)
+		}
+	}
+}
+
+func TestMidYearGrowthTimingBetweenConventions(t *testing.T) {
+	endBalance := func(timing string) float64 {
+		config := createTestConfig()
+		config.TSP.GrowthTiming = timing
+		results, err := NewCalculator(config).Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		return results.AnnualProjections[9].TSPEndBalance
+	}
+
+	begin := endBalance("begin_of_year")
+	mid := endBalance("mid_year")
+	end := endBalance("end_of_year")
+
+	if !(begin < mid && mid < end) {
+		t.Errorf("Expected begin < mid < end balances, got %.2f, %.2f, %.2f", begin, mid, end)
+	}
+}
+
+func TestSolveTargetNetIncome(t *testing.T) {
+	config := createTestConfig()
+	calc := NewCalculator(config)
+
+	results, err := calc.SolveTargetNetIncome(90000)
+	if err != nil {
+		t.Fatalf("SolveTargetNetIncome failed: %v", err)
+	}
+
+	first := results.AnnualProjections[0]
+	if math.Abs(first.NetIncome-90000) > 1 {
+		t.Errorf("Expected first-year net income near 90000, got %.2f", first.NetIncome)
+	}
+	if results.Summary.SolvedTSPWithdrawal != first.TSPWithdrawal || first.TSPWithdrawal <= 0 {
+		t.Errorf("Expected the summary to report the solved withdrawal %.2f, got %.2f",
+			first.TSPWithdrawal, results.Summary.SolvedTSPWithdrawal)
+	}
+
+	if _, err := calc.SolveTargetNetIncome(10000000); err == nil {
+		t.Error("Expected an error for an unreachable target")
+	}
+}
+
+func TestSolveTargetNetIncomeIgnoresLeavePayout(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.UnusedAnnualLeaveHours = 240
+
+	results, err := NewCalculator(config).SolveTargetNetIncome(90000)
+	if err != nil {
+		t.Fatalf("SolveTargetNetIncome failed: %v", err)
+	}
+
+	// The one-time payout is on top of the recurring net income the target asks for
+	if math.Abs(results.Summary.FirstYearIncome-90000) > 1 {
+		t.Errorf("Expected recurring first-year net income near 90000, got %.2f", results.Summary.FirstYearIncome)
+	}
+	if first := results.AnnualProjections[0]; first.NetIncome <= 90000+first.AnnualLeavePayout*0.5 {
+		t.Errorf("Expected the leave payout on top of the target, got first-year net %.2f", first.NetIncome)
+	}
+}
+
+func TestPaidFERSRedepositRestoresService(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SurvivorBenefit = "none"
+
+	base, err := NewCalculator(config).calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	config.Employment.CreditableService.RefundedService = []models.RefundedPeriod{
+		{
+			StartDate: time.Date(1992, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	unpaid, _ := NewCalculator(config).calculatePension()
+	if unpaid.BasePension != base.BasePension {
+		t.Errorf("Expected refunded service without a redeposit to add nothing, got %.2f vs %.2f", unpaid.BasePension, base.BasePension)
+	}
+
+	config.Employment.CreditableService.RefundedService[0].RedepositPaid = true
+	calc := NewCalculator(config)
+	paid, _ := calc.calculatePension()
+	years := calc.annuityServiceYears() - config.Employment.CreditableService.TotalYears
+	if math.Abs(years-3) > 0.01 {
+		t.Fatalf("Expected 3 restored years, got %.2f", years)
+	}
+	// Retiring at 62 with 20+ years earns the 1.1% multiplier
+	if diff := paid.BasePension - base.BasePension; math.Abs(diff-config.Employment.High3Salary*0.011*years) > 0.01 {
+		t.Errorf("Expected the redeposit to add %.2f to the annuity, added %.2f", config.Employment.High3Salary*0.011*years, diff)
+	}
+}
+
+func TestProjectedHigh3RaisesFutureAnnuity(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SurvivorBenefit = "none"
+	config.Employment.High3AsOf = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	config.Employment.SalaryGrowthRate = 0.02
+
+	held, err := NewCalculator(config).calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	config.Employment.ProjectHigh3 = true
+	calc := NewCalculator(config)
+	projected, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	if projected.BasePension <= held.BasePension {
+		t.Errorf("Expected a projected high-3 to raise the annuity, got %.2f vs %.2f held", projected.BasePension, held.BasePension)
+	}
+	if ratio := projected.BasePension / held.BasePension; math.Abs(ratio-calc.high3Salary()/config.Employment.High3Salary) > 1e-9 {
+		t.Errorf("Expected the annuity to scale with the projected high-3, got ratio %.4f", ratio)
+	}
+}
+
+func TestFamilyMaximumCapsHouseholdBenefits(t *testing.T) {
+	// 150% of the first 1643 of PIA
+	if max := calculateFamilyMaximum(1000); math.Abs(max-1500) > 0.01 {
+		t.Errorf("Expected a family maximum of 1500 for a 1000 PIA, got %.2f", max)
+	}
+
+	// The worker's 1000 plus 700 in spousal and other auxiliary benefits would exceed the
+	// 1500 family maximum, so the auxiliary benefits are cut to 500
+	if aux := capAuxiliaryBenefits(1000, 700); math.Abs(aux-500) > 0.01 {
+		t.Errorf("Expected auxiliary benefits capped at 500, got %.2f", aux)
+	}
+	if aux := capAuxiliaryBenefits(1000, 400); aux != 400 {
+		t.Errorf("Expected auxiliary benefits under the maximum to be unchanged, got %.2f", aux)
+	}
+
+	// The maximum stays between 150% and 188% of the PIA
+	for _, pia := range []float64{500, 1643, 2000, 2371, 3000, 4018} {
+		if ratio := calculateFamilyMaximum(pia) / pia; ratio < 1.5-1e-9 || ratio > 1.88 {
+			t.Errorf("Expected family maximum between 150%% and 188%% of PIA %.0f, got %.1f%%", pia, ratio*100)
+		}
+	}
+}
+
+func TestReplacementRatioBasis(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.FinalSalary = 90000
+
+	net, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.Retirement.ReplacementRatioBasis = "gross_final_salary"
+	gross, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	first := gross.AnnualProjections[0]
+	if expected := first.GrossIncome / 90000; math.Abs(gross.Summary.ReplacementRatio-expected) > 1e-9 {
+		t.Errorf("Expected gross/final-salary ratio %.4f, got %.4f", expected, gross.Summary.ReplacementRatio)
+	}
+	if gross.Summary.ReplacementRatio == net.Summary.ReplacementRatio {
+		t.Errorf("Expected the gross/final-salary ratio to differ from the net/high-3 ratio %.4f", net.Summary.ReplacementRatio)
+	}
+	if net.Summary.ReplacementRatioBasis != "net_high3" || gross.Summary.ReplacementRatioBasis != "gross_final_salary" {
+		t.Errorf("Expected the summary to report each basis, got %q and %q",
+			net.Summary.ReplacementRatioBasis, gross.Summary.ReplacementRatioBasis)
+	}
+}
+
+func TestUnsustainableWithdrawalRateWarns(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "percentage"
+	config.TSP.WithdrawalRate = 0.08
+	config.TSP.GrowthRate = models.Rate(0.07)
+	calc := NewCalculator(config)
+
+	if suggested := calc.calculateSustainableWithdrawalRate(); suggested >= 0.08 || suggested <= 0 {
+		t.Errorf("Expected a suggested rate below 8%%, got %.2f%%", suggested*100)
+	}
+	if !containsWarning(calc.generateWarnings(), "exceeds the sustainable rate") {
+		t.Error("Expected a warning for an 8% withdrawal rate with 7% growth")
+	}
+
+	config.TSP.WithdrawalRate = 0.03
+	if containsWarning(NewCalculator(config).generateWarnings(), "exceeds the sustainable rate") {
+		t.Error("Expected no warning for a 3% withdrawal rate")
+	}
+}
+
+func TestTaxableSSFlowsIntoIRMAAMAGI(t *testing.T) {
+	calc := NewCalculator(createTestConfig())
+
+	noSS := models.AnnualProjection{PensionIncome: 90000, TaxableTSPWithdrawal: 0, GrossIncome: 90000}
+	withSS := models.AnnualProjection{PensionIncome: 90000, SocialSecurityIncome: 30000, GrossIncome: 120000}
+
+	if magi := calc.calculateMAGI(noSS); magi != 90000 || calculateIRMAATier(magi, 66, singleSchedule) != 0 {
+		t.Errorf("Expected MAGI 90000 in tier 0 without Social Security, got %.2f", magi)
+	}
+
+	// 85% of the benefit is taxable at this income, pushing MAGI past the first threshold
+	magi := calc.calculateMAGI(withSS)
+	if math.Abs(magi-(90000+30000*0.85)) > 0.01 {
+		t.Errorf("Expected MAGI to include the taxable 85%% of Social Security, got %.2f", magi)
+	}
+	if tier := calculateIRMAATier(magi, 66, singleSchedule); tier != 1 {
+		t.Errorf("Expected IRMAA tier 1, got %d", tier)
+	}
+
+	taxable := calc.calculateFederalTaxableIncome(withSS, 66)
+	if math.Abs(magi-taxable-(14700+1850)) > 0.01 {
+		t.Errorf("Expected federal taxable income to use the same taxable Social Security as MAGI")
+	}
+}
+
+func TestJointFilersUseJointScheduleUntilDeath(t *testing.T) {
+	config := createTestConfig()
+	config.TaxInfo.FilingStatus = "mfj"
+	config.Personal.SpouseBirthDate = time.Date(1967, 6, 1, 0, 0, 0, 0, time.UTC)
+	calc := NewCalculator(config)
+	single := NewCalculator(createTestConfig())
+
+	year := models.AnnualProjection{PensionIncome: 30000, SocialSecurityIncome: 30000, GrossIncome: 60000}
+
+	// Joint thresholds leave less of the benefit taxable, and the wider brackets and
+	// doubled deduction leave less tax
+	if joint, alone := calc.calculateAGI(year), single.calculateAGI(year); joint >= alone {
+		t.Errorf("Expected a smaller joint AGI than %.2f, got %.2f", alone, joint)
+	}
+	if joint, alone := calc.calculateFederalTax(year, 66), single.calculateFederalTax(year, 66); joint >= alone {
+		t.Errorf("Expected less joint federal tax than %.2f, got %.2f", alone, joint)
+	}
+
+	// Both filers are 65 or older, so each adds the joint senior deduction
+	taxable := calc.calculateFederalTaxableIncome(year, 66)
+	if expected := calc.calculateAGI(year) - (29400 + 2*1500); math.Abs(taxable-expected) > 0.01 {
+		t.Errorf("Expected joint taxable income %.2f, got %.2f", expected, taxable)
+	}
+
+	// After the retiree's death the survivor files single
+	year.RetireeDeceased = true
+	if survivor, alone := calc.calculateFederalTax(year, 66), single.calculateFederalTax(year, 66); survivor != alone {
+		t.Errorf("Expected the survivor to pay the single tax %.2f, got %.2f", alone, survivor)
+	}
+	if calculateIRMAATier(250000, 66, jointSchedule) != 1 || calculateIRMAATier(250000, 66, singleSchedule) != 4 {
+		t.Error("Expected doubled IRMAA thresholds for joint filers")
+	}
+	if single, joint := federalBracketTop(0.24, singleSchedule), federalBracketTop(0.24, jointSchedule); single != 182100 || joint != 364200 {
+		t.Errorf("Expected the 24%% brackets to end at 182100 and 364200, got %.0f and %.0f", single, joint)
+	}
+}
+
+func TestNetReplacementRatioExceedsGrossBasis(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.TSPContributionRate = 0.05
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	summary := results.Summary
+	if summary.WorkingNetIncome <= 0 || summary.WorkingNetIncome >= config.Employment.High3Salary*0.95 {
+		t.Errorf("Expected working net income below salary less TSP contributions, got %.2f", summary.WorkingNetIncome)
+	}
+	if summary.NetReplacementRatio <= summary.ReplacementRatio {
+		t.Errorf("Expected the net replacement ratio %.3f to exceed the high-3 based ratio %.3f",
+			summary.NetReplacementRatio, summary.ReplacementRatio)
+	}
+}
+
+func TestWorkingYearsUseCurrentFEHBPremium(t *testing.T) {
+	config := createTestConfig()
+	config.HealthInsurance.CurrentPremium = 3000
+	config.Retirement.PhasedRetirement = &models.PhasedRetirementInfo{Years: 2, PartTimeSalary: 41000}
+	calc := NewCalculator(config)
+	working := calc.calculateWorkingNetIncome()
+
+	// A higher retirement premium applies only once fully retired
+	config.HealthInsurance.RetirementPremium = 6000
+	calc = NewCalculator(config)
+	if got := calc.calculateWorkingNetIncome(); got != working {
+		t.Errorf("Expected working take-home %.2f to use the current premium, got %.2f", working, got)
+	}
+	startAge := calc.calculateAgeAtRetirement()
+	if got := calc.calculateHealthInsurance(startAge + 1); got != 3000 {
+		t.Errorf("Expected the current premium during phased retirement, got %.2f", got)
+	}
+	if got := calc.calculateHealthInsurance(startAge + 2); got != 6000 {
+		t.Errorf("Expected the retirement premium after phased retirement, got %.2f", got)
+	}
+}
+
+func TestTrustFundCutReducesSocialSecurity(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.ClaimingAge = 62
+	base, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	config.SocialSecurity.TrustFundCutYear = 2033
+	config.SocialSecurity.TrustFundCut = 0.23
+	cut, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, p := range cut.AnnualProjections {
+		before := base.AnnualProjections[i]
+		expected := before.SocialSecurityIncome
+		if config.Personal.BirthDate.Year()+p.Age >= 2033 {
+			expected *= 0.77
+		}
+		if math.Abs(p.SocialSecurityIncome-expected) > 0.01 {
+			t.Errorf("Expected Social Security %.2f at age %d, got %.2f", expected, p.Age, p.SocialSecurityIncome)
+		}
+		if p.PensionIncome != before.PensionIncome {
+			t.Errorf("Expected pension unaffected at age %d, got %.2f vs %.2f", p.Age, p.PensionIncome, before.PensionIncome)
+		}
+	}
+}
+
+func TestSurplusIsNetIncomeLessInflatedSpending(t *testing.T) {
+	config := createTestConfig()
+	config.Spending.AnnualAmount = 70000
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, p := range results.AnnualProjections {
+		spending := 70000 * math.Pow(1+calc.inflationRate(), float64(i))
+		if math.Abs(p.RequiredSpending-spending) > 0.01 {
+			t.Errorf("Expected spending %.2f at age %d, got %.2f", spending, p.Age, p.RequiredSpending)
+		}
+		if math.Abs(p.Surplus-(p.NetIncome-spending)) > 0.01 {
+			t.Errorf("Expected surplus %.2f at age %d, got %.2f", p.NetIncome-spending, p.Age, p.Surplus)
+		}
+	}
+}
+
+func TestMortalityWeightedLifetimeIncome(t *testing.T) {
+	config := createTestConfig()
+	config.Assumptions.MortalityWeighted = true
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	summary := results.Summary
+	if summary.ExpectedLifetimeIncome <= 0 || summary.ExpectedLifetimeIncome >= summary.LifetimeIncome {
+		t.Errorf("Expected mortality-weighted income below the undiscounted %.2f, got %.2f",
+			summary.LifetimeIncome, summary.ExpectedLifetimeIncome)
+	}
+	if p := survivalProbability(62, 62); p != 1 {
+		t.Errorf("Expected certain survival over zero years, got %.4f", p)
+	}
+}
+
+func TestRothOnlyTSPHasNoRMD(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.TraditionalBalance = 0
+	config.TSP.RothBalance = 500000
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 0
+	config.TSP.WithdrawalCeiling = 100000 // Guardrails turn on the RMD floor
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, p := range results.AnnualProjections {
+		if p.Age == 75 && p.TSPWithdrawal != 0 {
+			t.Errorf("Expected no forced withdrawal at 75 from Roth TSP, got %.2f", p.TSPWithdrawal)
+		}
+	}
+
+	// Proportional draws keep the Traditional half of the balance, so only that half has an RMD
+	config.TSP.TraditionalBalance = 500000
+	config.TSP.RothBalance = 500000
+	config.TSP.WithdrawalSource = "proportional"
+	results, err = NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, p := range results.AnnualProjections {
+		if p.Age == 75 && math.Abs(p.TSPWithdrawal-p.TSPStartBalance/2/24.6) > 0.01 {
+			t.Errorf("Expected RMD on the Traditional half only, got %.2f", p.TSPWithdrawal)
+		}
+	}
+}
+
+func TestRMDFollowsTrackedTraditionalBalance(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.TraditionalBalance = 300000
+	config.TSP.RothBalance = 300000
+	config.TSP.WithdrawalStrategy = "custom_schedule"
+	config.TSP.WithdrawalSchedule = map[int]float64{62: 200000}
+
+	// The default source takes the age-62 withdrawal from Traditional, so the RMD at 75 is
+	// on what remains of the Traditional balance, not on half of the account
+	calc := NewCalculator(config)
+	projections, err := calc.generateAnnualProjections(models.PensionCalculation{}, models.SocialSecurityCalculation{ClaimingAge: 67}, models.FERSSupplementCalculation{})
+	if err != nil {
+		t.Fatalf("generateAnnualProjections failed: %v", err)
+	}
+	// End-of-year growth credits the first year on the balance before the withdrawal
+	g := *config.TSP.GrowthRate
+	traditional := (300000*(1+g) - 200000) * math.Pow(1+g, float64(75-63))
+	for _, p := range projections {
+		if p.Age == 75 && math.Abs(p.TSPWithdrawal-traditional/24.6) > 0.01 {
+			t.Errorf("Expected an RMD of %.2f on the remaining Traditional balance, got %.2f", traditional/24.6, p.TSPWithdrawal)
+		}
+	}
+}
+
+func TestPhasedRetirementSuspendsSupplement(t *testing.T) {
+	// Retire at 58 with 30 years, past the MRA, so the supplement is payable
+	config := createTestConfig()
+	config.Retirement.TargetRetirementDate = time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	config.Employment.CreditableService.TotalYears = 30
+	config.Retirement.PhasedRetirement = &models.PhasedRetirementInfo{
+		Years:          2,
+		PartTimeSalary: 41000,
+	}
+
+	// Evaluate before the retirement date so the projection starts at retirement
+	calculator := NewCalculator(config)
+	calculator.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results, err := calculator.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, proj := range results.AnnualProjections[:3] {
+		if i < 2 && proj.FERSSupplementIncome != 0 {
+			t.Errorf("Age %d: expected no supplement during phased retirement, got %.2f", proj.Age, proj.FERSSupplementIncome)
+		}
+		if i == 2 && proj.FERSSupplementIncome <= 0 {
+			t.Errorf("Age %d: expected the supplement after full retirement", proj.Age)
+		}
+	}
+}
+
+func TestPhasedRetirementTSPContributions(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.TSPContributionRate = 0.05
+	config.Retirement.PhasedRetirement = &models.PhasedRetirementInfo{
+		Years:          2,
+		PartTimeSalary: 41000,
+	}
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	for i, proj := range results.AnnualProjections[:3] {
+		expected := 0.0
+		if i < 2 {
+			expected = 41000 * 0.05
+		}
+		if proj.TSPContribution != expected {
+			t.Errorf("Year %d: expected TSP contribution %.2f, got %.2f", i, expected, proj.TSPContribution)
+		}
+		balance := proj.TSPStartBalance + proj.TSPGrowth - proj.TSPWithdrawal + proj.TSPContribution
+		if math.Abs(proj.TSPEndBalance-balance) > 0.01 {
+			t.Errorf("Year %d: expected end balance %.2f to include contributions, got %.2f", i, balance, proj.TSPEndBalance)
+		}
+	}
+	if err := CheckAccounting(results.AnnualProjections); err != nil {
+		t.Errorf("Accounting check failed with contributions: %v", err)
+	}
+}
+
+func TestCSRSExcessContributionRefund(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.RetirementSystem = "CSRS"
+	config.Employment.CreditableService.TotalYears = 43
+
+	calc := NewCalculator(config)
+	pension, err := calc.calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	if expected := 82000 * 0.80; math.Abs(pension.BasePension-expected) > 0.01 {
+		t.Errorf("Expected annuity capped at 80%% of high-3 (%.2f), got %.2f", expected, pension.BasePension)
+	}
+
+	excess := 43 - 41.875
+	expected := excess * 82000 * 0.07 * math.Pow(1.03, excess/2)
+	if math.Abs(pension.ExcessContributionRefund-expected) > 0.01 {
+		t.Errorf("Expected excess contribution refund %.2f, got %.2f", expected, pension.ExcessContributionRefund)
+	}
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if got := results.AnnualProjections[0].ContributionRefund; got != pension.ExcessContributionRefund {
+		t.Errorf("Expected the refund in the first projection year, got %.2f", got)
+	}
+	if got := results.AnnualProjections[1].ContributionRefund; got != 0 {
+		t.Errorf("Expected no refund after the first year, got %.2f", got)
+	}
+
+	// The refund is after-tax money, so it adds neither income nor tax
+	first := results.AnnualProjections[0]
+	withoutRefund := first
+	withoutRefund.ContributionRefund = 0
+	if calculateGrossIncome(first) != calculateGrossIncome(withoutRefund) {
+		t.Error("Expected the refund to be left out of gross income")
+	}
+	if calc.calculateFederalTax(first, first.Age) != calc.calculateFederalTax(withoutRefund, first.Age) ||
+		calc.calculateStateTax(first, first.Age) != calc.calculateStateTax(withoutRefund, first.Age) {
+		t.Error("Expected the refund to add no federal or state tax")
+	}
+
+	config.Retirement.DisableExcessRefund = true
+	if refund := NewCalculator(config).calculateExcessContributionRefund(43); refund != 0 {
+		t.Errorf("Expected no refund when disabled, got %.2f", refund)
+	}
+}
+
+func TestSupplementGapSuggestsClaimingAt62(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.CreditableService.TotalYears = 30
+	config.Retirement.TargetRetirementDate = time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC) // MRA+30 at 57
+	config.SocialSecurity.ClaimingAge = 67
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	var supplement float64
+	for _, p := range results.AnnualProjections {
+		if p.Age == 61 {
+			supplement = p.FERSSupplementIncome
+		}
+	}
+	if supplement <= 0 {
+		t.Fatal("Expected a FERS supplement through age 61")
+	}
+
+	summary := results.Summary
+	if summary.SupplementGapYears != 5 {
+		t.Errorf("Expected a 5-year gap from 62 to 67, got %d", summary.SupplementGapYears)
+	}
+	if expected := supplement * 5; math.Abs(summary.SupplementGapLoss-expected) > 0.01 {
+		t.Errorf("Expected gap loss %.2f, got %.2f", expected, summary.SupplementGapLoss)
+	}
+	if len(summary.Suggestions) != 1 || !strings.Contains(summary.Suggestions[0], "Claim Social Security at 62") {
+		t.Errorf("Expected a suggestion to claim at 62, got %v", summary.Suggestions)
+	}
+
+	config.SocialSecurity.ClaimingAge = 62
+	results, err = NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.SupplementGapYears != 0 || len(results.Summary.Suggestions) != 0 {
+		t.Errorf("Expected no gap or suggestion when claiming at 62, got %+v", results.Summary.Suggestions)
+	}
+}
+
+func TestBracketFillLowersLifetimeTax(t *testing.T) {
+	lifetimeTax := func(source string) (float64, []models.AnnualProjection) {
+		config := createTestConfig()
+		config.TSP.TraditionalBalance = 200000
+		config.TSP.RothBalance = 400000
+		config.TSP.WithdrawalStrategy = "fixed_amount"
+		config.TSP.WithdrawalAmount = 60000
+		config.TSP.WithdrawalSource = source
+
+		results, err := NewCalculator(config).Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		var total float64
+		for _, p := range results.AnnualProjections {
+			total += p.FederalTax
+		}
+		return total, results.AnnualProjections
+	}
+
+	proportional, _ := lifetimeTax("proportional")
+	bracketFill, projections := lifetimeTax("bracket_fill")
+	if bracketFill >= proportional {
+		t.Errorf("Expected bracket filling to lower lifetime federal tax below %.2f, got %.2f", proportional, bracketFill)
+	}
+
+	// While both balances last, taxable income stays within the 12% bracket
+	first := projections[0]
+	if first.TaxableTSPWithdrawal <= 0 || first.TaxableTSPWithdrawal >= first.TSPWithdrawal {
+		t.Errorf("Expected a partly Traditional first-year withdrawal, got %.2f of %.2f",
+			first.TaxableTSPWithdrawal, first.TSPWithdrawal)
+	}
+	if first.TopBracketRate > 0.12 {
+		t.Errorf("Expected the first year to stay within the 12%% bracket, got %.0f%%", first.TopBracketRate*100)
+	}
+}
+
+func TestSSEstimateInconsistentWithPIAWarns(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.EstimatedPIA = 2800
+	config.SocialSecurity.MonthlyEstimates = map[int]float64{67: 2000}
+
+	if !containsWarning(NewCalculator(config).generateWarnings(), "differs from the PIA") {
+		t.Error("Expected a consistency warning when the FRA estimate contradicts the PIA")
+	}
+
+	config.SocialSecurity.MonthlyEstimates = map[int]float64{67: 2810}
+	if containsWarning(NewCalculator(config).generateWarnings(), "differs from the PIA") {
+		t.Error("Expected no warning when the FRA estimate is within tolerance of the PIA")
+	}
+}
+
+func TestComparisonBaselineDeltas(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 60000
+	config.TSP.GrowthTiming = "begin_of_year"
+
+	comparison, err := CompareRetirementAges(config, []string{"60", "62"})
+	if err != nil {
+		t.Fatalf("CompareRetirementAges failed: %v", err)
+	}
+	if err := SetComparisonBaseline(comparison, 1); err != nil {
+		t.Fatalf("SetComparisonBaseline failed: %v", err)
+	}
+
+	baseline := comparison.Deltas[1]
+	if baseline.LifetimeIncome != 0 || baseline.TSPDepletionDelta == nil || *baseline.TSPDepletionDelta != 0 {
+		t.Errorf("Expected zero deltas on the baseline row, got %+v", baseline)
+	}
+
+	other := comparison.Deltas[0]
+	expected := comparison.Scenarios[0].Summary.LifetimeIncome - comparison.Scenarios[1].Summary.LifetimeIncome
+	if other.LifetimeIncome != expected || expected == 0 {
+		t.Errorf("Expected a signed lifetime income delta of %.2f, got %.2f", expected, other.LifetimeIncome)
+	}
+	depletion := comparison.Scenarios[0].Summary.TSPProjectedDepletion - comparison.Scenarios[1].Summary.TSPProjectedDepletion
+	if other.TSPDepletionDelta == nil || *other.TSPDepletionDelta != depletion || depletion >= 0 {
+		t.Errorf("Expected an earlier depletion (%d years) for retiring at 60, got %v", depletion, other.TSPDepletionDelta)
+	}
+
+	if err := SetComparisonBaseline(comparison, 2); err == nil {
+		t.Error("Expected an error for a baseline outside the scenarios")
+	}
+}
+
+func TestCompareRetirementAgesAcrossCOLAAndSupplementBoundary(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SurvivorBenefit = "none"
+
+	comparison, err := CompareRetirementAges(config, []string{"60", "62"})
+	if err != nil {
+		t.Fatalf("CompareRetirementAges failed: %v", err)
+	}
+	at60, at62 := comparison.Scenarios[0], comparison.Scenarios[1]
+
+	if at60.Summary.FERSSupplement <= 0 {
+		t.Error("Expected the FERS supplement in the age 60 scenario")
+	}
+	if at62.Summary.FERSSupplement != 0 {
+		t.Errorf("Expected no FERS supplement in the age 62 scenario, got %.2f", at62.Summary.FERSSupplement)
+	}
+
+	// Retiring at 60: no COLA before 62, then one COLA at 62 rather than two
+	cola := NewCalculator(config).calculateFERSCOLA(NewCalculator(config).colaRate())
+	pension := at60.AnnualProjections
+	if pension[1].PensionIncome != pension[0].PensionIncome {
+		t.Errorf("Expected no COLA at 61, got %.2f after %.2f", pension[1].PensionIncome, pension[0].PensionIncome)
+	}
+	if expected := pension[0].PensionIncome * (1 + cola); math.Abs(pension[2].PensionIncome-expected) > 0.01 {
+		t.Errorf("Expected a single COLA at 62 (%.2f), got %.2f", expected, pension[2].PensionIncome)
+	}
+
+	// Retiring at 62: the COLA begins the following year
+	pension = at62.AnnualProjections
+	if expected := pension[0].PensionIncome * (1 + cola); math.Abs(pension[1].PensionIncome-expected) > 0.01 {
+		t.Errorf("Expected a COLA at 63 (%.2f), got %.2f", expected, pension[1].PensionIncome)
+	}
+}
+
+func TestCSRSGetsFullCOLABefore62(t *testing.T) {
+	pensionGrowth := func(system string) float64 {
+		config := createTestConfig()
+		config.Personal.RetirementSystem = system
+		config.Personal.BirthDate = time.Date(1970, 3, 15, 0, 0, 0, 0, time.UTC)
+		config.Retirement.TargetRetirementDate = time.Date(2028, 3, 15, 0, 0, 0, 0, time.UTC)
+		config.Employment.CreditableService.TotalYears = 30
+		config.Assumptions.COLARate = models.Rate(0.03)
+
+		calculator := NewCalculator(config)
+		calculator.now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		results, err := calculator.Calculate()
+		if err != nil {
+			t.Fatalf("Calculate failed for %s: %v", system, err)
+		}
+		if age := results.AnnualProjections[0].Age; age != 58 {
+			t.Fatalf("Expected the %s projection to start at 58, got %d", system, age)
+		}
+		return results.AnnualProjections[1].PensionIncome / results.AnnualProjections[0].PensionIncome
+	}
+
+	// CSRS gets the full 3% at 59, not the 2% FERS diet COLA
+	if growth := pensionGrowth("CSRS"); math.Abs(growth-1.03) > 1e-9 {
+		t.Errorf("Expected the CSRS pension to grow by the full 3%% COLA at 59, got %.4f", growth)
+	}
+	if growth := pensionGrowth("FERS"); growth != 1 {
+		t.Errorf("Expected no FERS COLA at 59, got growth of %.4f", growth)
+	}
+}
+
+func TestCompareRetirementAgesSwitchesMultiplierAt62(t *testing.T) {
+	config := createTestConfig()
+	config.Retirement.SurvivorBenefit = "none"
+
+	comparison, err := CompareRetirementAges(config, []string{"60", "62"})
+	if err != nil {
+		t.Fatalf("CompareRetirementAges failed: %v", err)
+	}
+
+	expected := []float64{0.010, 0.011}
+	for i, scenario := range comparison.Scenarios {
+		summary := scenario.Summary
+		multiplier := summary.AnnualPension / (config.Employment.High3Salary * summary.CreditableService)
+		if math.Abs(multiplier-expected[i]) > 1e-9 {
+			t.Errorf("Scenario %d (age %d): expected %.1f%% multiplier, got %.4f%%",
+				i, scenario.AnnualProjections[0].Age, expected[i]*100, multiplier*100)
+		}
+	}
+
+	if comparison.Scenarios[0].Summary.CreditableService >= comparison.Scenarios[1].Summary.CreditableService {
+		t.Error("Expected the later retirement to accrue more service")
+	}
+}
+
+func TestSurvivorAnnuityGrowsWithCOLAAfterDeath(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.AssumedDeathAge = 80
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	pension, _ := calc.calculatePension()
+	survivor := calc.calculateSurvivorAnnuity(pension)
+
+	var previous float64
+	for _, proj := range results.AnnualProjections {
+		if proj.Age < 80 {
+			continue
+		}
+		if !proj.RetireeDeceased {
+			t.Errorf("Expected age %d to be marked after the retiree's death", proj.Age)
+		}
+		if proj.SocialSecurityIncome != 0 {
+			t.Errorf("Expected the retiree's own Social Security to stop at death, got %.2f at %d", proj.SocialSecurityIncome, proj.Age)
+		}
+		if proj.PensionIncome <= survivor {
+			t.Errorf("Expected the survivor annuity at age %d to include COLAs above %.2f, got %.2f", proj.Age, survivor, proj.PensionIncome)
+		}
+		if previous > 0 && proj.PensionIncome <= previous {
+			t.Errorf("Expected the survivor annuity to grow after death: age %d %.2f <= %.2f", proj.Age, proj.PensionIncome, previous)
+		}
+		previous = proj.PensionIncome
+	}
+}
+
+func TestSurvivorAnnuityHasNoFERSCOLABefore62(t *testing.T) {
+	// MRA+30 at 57; the retiree dies at 59, before any FERS COLA is paid
+	config := createTestConfig()
+	config.Personal.BirthDate = time.Date(1970, 6, 1, 0, 0, 0, 0, time.UTC)
+	config.Employment.HireDate = time.Date(1995, 6, 1, 0, 0, 0, 0, time.UTC)
+	config.Retirement.TargetRetirementDate = time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	config.Employment.CreditableService.TotalYears = 32
+	config.Personal.AssumedDeathAge = 59
+	calc := NewCalculator(config)
+
+	results, err := calc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	pension, _ := calc.calculatePension()
+	survivor := calc.calculateSurvivorAnnuity(pension)
+
+	income := make(map[int]float64)
+	for _, proj := range results.AnnualProjections {
+		income[proj.Age] = proj.PensionIncome
+	}
+	for age := 59; age <= 61; age++ {
+		if math.Abs(income[age]-survivor) > 0.01 {
+			t.Errorf("Expected the survivor annuity of %.2f without COLAs at age %d, got %.2f", survivor, age, income[age])
+		}
+	}
+	if expected := survivor * (1 + calc.calculateFERSCOLA(calc.colaRate())); math.Abs(income[62]-expected) > 0.01 {
+		t.Errorf("Expected one FERS COLA on the survivor annuity at 62 (%.2f), got %.2f", expected, income[62])
+	}
+}
+
+func TestCheckAccounting(t *testing.T) {
+	config := createTestConfig()
+	config.HealthInsurance.RetirementPremium = 6000
+	config.TaxInfo.State = "VA"
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if err := CheckAccounting(results.AnnualProjections); err != nil {
+		t.Fatalf("Expected consistent accounting, got %v", err)
+	}
+
+	// Corrupting a deduction component must break the identity
+	corrupted := append([]models.AnnualProjection(nil), results.AnnualProjections...)
+	corrupted[5].StateTax += 100
+	if err := CheckAccounting(corrupted); err == nil {
+		t.Error("Expected a corrupted state tax to fail the accounting check")
+	}
+
+	corrupted = append([]models.AnnualProjection(nil), results.AnnualProjections...)
+	corrupted[3].NetIncome += 1
+	if err := CheckAccounting(corrupted); err == nil {
+		t.Error("Expected a corrupted net income to fail the accounting check")
+	}
+}
+
+func TestUnpaidPre1982DepositReducesCSRSAnnuity(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.RetirementSystem = "CSRS"
+	config.Retirement.SurvivorBenefit = "none"
+
+	base, err := NewCalculator(config).calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	config.Employment.CreditableService.NonDeductionPeriods = []models.NonDeductionPeriod{
+		{
+			StartDate:   time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(1981, 5, 31, 0, 0, 0, 0, time.UTC),
+			DepositOwed: 3000,
+		},
+	}
+	reduced, err := NewCalculator(config).calculatePension()
+	if err != nil {
+		t.Fatalf("calculatePension failed: %v", err)
+	}
+
+	if reduced.DepositReduction != 300 {
+		t.Errorf("Expected a $300 reduction (10%% of the $3000 deposit), got %.2f", reduced.DepositReduction)
+	}
+	if diff := base.FinalPension - reduced.FinalPension; math.Abs(diff-300) > 0.01 {
+		t.Errorf("Expected the annuity to drop by $300, dropped by %.2f", diff)
+	}
+
+	config.Employment.CreditableService.NonDeductionPeriods[0].DepositPaid = true
+	paid, _ := NewCalculator(config).calculatePension()
+	if paid.DepositReduction != 0 {
+		t.Errorf("Expected no reduction once the deposit is paid, got %.2f", paid.DepositReduction)
+	}
+}
+
+func TestRetirementAgeOverride(t *testing.T) {
+	config := createTestConfig()
+
+	scenario, err := applyScenarioOverrides(config, []ScenarioOverride{{Name: "age", Value: "60"}})
+	if err != nil {
+		t.Fatalf("applyScenarioOverrides failed: %v", err)
+	}
+
+	expected := time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC)
+	if !scenario.Retirement.TargetRetirementDate.Equal(expected) {
+		t.Errorf("Expected retirement date %v, got %v", expected, scenario.Retirement.TargetRetirementDate)
+	}
+	if diff := config.Employment.CreditableService.TotalYears - scenario.Employment.CreditableService.TotalYears; math.Abs(diff-2) > 0.01 {
+		t.Errorf("Expected two fewer years of service, got %.2f fewer", diff)
+	}
+	if !config.Retirement.TargetRetirementDate.Equal(time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the base config to be left unchanged")
+	}
+
+	if _, err := applyScenarioOverrides(config, []ScenarioOverride{{Name: "pension", Value: "1"}}); err == nil {
+		t.Error("Expected an error for an unknown override")
+	}
+}
+
+func TestStrategyOverrideDropsFixedAmountOptions(t *testing.T) {
+	config := createTestConfig()
+	config.TSP.WithdrawalStrategy = "fixed_amount"
+	config.TSP.WithdrawalAmount = 30000
+	config.TSP.WithdrawalBasis = "net"
+	config.TSP.WithdrawalCOLA = 0.02
+
+	scenario, err := applyScenarioOverrides(config, []ScenarioOverride{{Name: "strategy", Value: "percentage"}})
+	if err != nil {
+		t.Fatalf("applyScenarioOverrides failed: %v", err)
+	}
+	if scenario.TSP.WithdrawalBasis != "" || scenario.TSP.WithdrawalCOLA != 0 {
+		t.Errorf("Expected the net basis and withdrawal COLA dropped, got %q and %.2f",
+			scenario.TSP.WithdrawalBasis, scenario.TSP.WithdrawalCOLA)
+	}
+	if config.TSP.WithdrawalBasis != "net" || config.TSP.WithdrawalCOLA != 0.02 {
+		t.Error("Expected the base config to be left unchanged")
+	}
+
+	// Every strategy the config accepts is accepted as an override, and nothing else
+	for _, strategy := range []string{"bequest", "income_only", "life_expectancy", "lump_sum"} {
+		if _, err := applyScenarioOverrides(config, []ScenarioOverride{{Name: "strategy", Value: strategy}}); err != nil {
+			t.Errorf("Expected the %s strategy accepted, got %v", strategy, err)
+		}
+	}
+	if _, err := applyScenarioOverrides(config, []ScenarioOverride{{Name: "strategy", Value: "annuity"}}); err == nil {
+		t.Error("Expected an unknown strategy rejected")
+	}
+}
+
+func TestServiceYearsUseCalendarDuration(t *testing.T) {
+	hire := time.Date(1999, 1, 15, 0, 0, 0, 0, time.UTC)
+	retirement := time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	if years, months, days := ServiceDuration(hire, retirement); years != 30 || months != 2 || days != 0 {
+		t.Errorf("Expected 30 years, 2 months, 0 days, got %d years, %d months, %d days", years, months, days)
+	}
+	if service := ServiceYears(hire, retirement); math.Abs(service-(30+2.0/12)) > 1e-9 {
+		t.Errorf("Expected exactly 30 years and 2 months of service, got %.6f", service)
+	}
+
+	// Days borrow a 30-day month, regardless of leap years
+	if years, months, days := ServiceDuration(hire, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)); years != 25 || months != 1 || days != 16 {
+		t.Errorf("Expected 25 years, 1 month, 16 days, got %d years, %d months, %d days", years, months, days)
+	}
+}
+
+func TestSpousalSSIncome(t *testing.T) {
+	config := createTestConfig()
+	config.Personal.SpouseBirthDate = time.Date(1970, 6, 1, 0, 0, 0, 0, time.UTC) // 3 years younger
+	config.SocialSecurity.SpouseBenefit = &models.SpouseBenefit{EstimatedPIA: 1000, ClaimingAge: 67}
+	calc := NewCalculator(config)
+	ss := calc.calculateSocialSecurity()
+
+	// Paid from the year the spouse reaches their claiming age, at retiree age 70
+	if spousal := calc.calculateSpousalSSIncome(ss, 69); spousal != 0 {
+		t.Errorf("Expected no spousal benefit before the spouse claims, got %.2f", spousal)
+	}
+	expected := (2800*0.5 - 1000) * 12 * math.Pow(1+calc.colaRate(), 3)
+	if spousal := calc.calculateSpousalSSIncome(ss, 70); math.Abs(spousal-expected) > 0.01 {
+		t.Errorf("Expected the excess of half the PIA over the spouse's own, %.2f, got %.2f", expected, spousal)
+	}
+
+	// A spouse with a small PIA of their own gets at most half the retiree's PIA
+	config.SocialSecurity.SpouseBenefit.EstimatedPIA = 1
+	maxSpousal := 2800 * 0.5 * 12 * math.Pow(1+calc.colaRate(), 3)
+	if spousal := calc.calculateSpousalSSIncome(ss, 70); spousal > maxSpousal || spousal < maxSpousal-12*1.1 {
+		t.Errorf("Expected the spousal benefit capped at half the PIA, %.2f, got %.2f", maxSpousal, spousal)
+	}
+
+	// A spouse whose own PIA is at least half the retiree's gets nothing extra
+	config.SocialSecurity.SpouseBenefit.EstimatedPIA = 1400
+	if spousal := calc.calculateSpousalSSIncome(ss, 70); spousal != 0 {
+		t.Errorf("Expected no spousal benefit over an own PIA of half the retiree's, got %.2f", spousal)
+	}
+}
+
+func TestSpousalSSIsTaxedWithOwnBenefit(t *testing.T) {
+	calc := NewCalculator(createTestConfig())
+	own := models.AnnualProjection{PensionIncome: 40000, SocialSecurityIncome: 30000}
+	own.GrossIncome = own.PensionIncome + own.SocialSecurityIncome
+
+	withSpousal := own
+	withSpousal.SpousalSSIncome = 15000
+	withSpousal.GrossIncome += withSpousal.SpousalSSIncome
+
+	// Provisional income counts half of both benefits rather than all of the spousal one
+	householdAGI := calc.calculateAGI(withSpousal)
+	expected := 40000 + calculateTaxableSS(45000, 85000, singleSchedule)
+	if math.Abs(householdAGI-expected) > 0.01 {
+		t.Errorf("Expected AGI %.2f taxing both benefits together, got %.2f", expected, householdAGI)
+	}
+	if householdAGI <= calc.calculateAGI(own) {
+		t.Errorf("Expected the spousal benefit to add to AGI, got %.2f", householdAGI)
+	}
+	if magi := calc.calculateMAGI(withSpousal); magi != householdAGI {
+		t.Errorf("Expected MAGI to include the taxable spousal benefit, got %.2f against AGI %.2f", magi, householdAGI)
+	}
+}
+
+func TestAsOfSetsEvaluationDate(t *testing.T) {
+	config := createTestConfig()
+	config.Assumptions.AsOf = time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if first := results.AnnualProjections[0]; first.Age != 64 || first.Year != 2031 {
+		t.Errorf("Expected the projection to start at age 64 in 2031 as of mid-2031, got age %d (%d)", first.Age, first.Year)
+	}
+	if !results.Metadata.CalculationDate.Equal(config.Assumptions.AsOf) {
+		t.Errorf("Expected the calculation date to be the as-of date, got %v", results.Metadata.CalculationDate)
+	}
+}
+
+func TestAlreadyRetiredSummaryHasNoRetirementLumpSums(t *testing.T) {
+	config := createTestConfig()
+	config.Employment.UnusedAnnualLeaveHours = 240
+
+	results, err := NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.AnnualLeavePayout <= 0 {
+		t.Fatal("Expected a leave payout when the projection starts at retirement")
+	}
+
+	// Two years after retiring, the payout was already received before the projection
+	config.Assumptions.AsOf = time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+	results, err = NewCalculator(config).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if results.Summary.AnnualLeavePayout != 0 || results.AnnualProjections[0].AnnualLeavePayout != 0 {
+		t.Errorf("Expected no leave payout for an already-retired plan, got %.2f in the summary",
+			results.Summary.AnnualLeavePayout)
+	}
+}
+
+func TestPIAFromFlatEarningsHistory(t *testing.T) {
+	// $60,000 a year in 2025 wage terms for 35 years gives an AIME of $5,000
+	ss := models.SocialSecurityInfo{EarningsHistory: map[int]float64{}}
+	for year := 1991; year < 2026; year++ {
+		ss.EarningsHistory[year] = 60000 / math.Pow(1+wageGrowthRate, float64(earningsIndexYear-year))
+	}
+
+	expected := math.Floor((0.90*1226+0.32*(5000-1226))*10) / 10
+	if pia := PIAFromEarnings(ss); math.Abs(pia-expected) > 0.1 {
+		t.Errorf("Expected a PIA of $%.2f from the bend-point formula, got $%.2f", expected, pia)
+	}
+
+	// Only the highest 35 years count, and a missing year counts as zero
+	ss.EarningsHistory[1985] = 1000
+	if pia := PIAFromEarnings(ss); math.Abs(pia-expected) > 0.1 {
+		t.Errorf("Expected a 36th, lower year not to change the PIA, got $%.2f", pia)
+	}
+	delete(ss.EarningsHistory, 1985)
+	delete(ss.EarningsHistory, 2000)
+	if pia := PIAFromEarnings(ss); pia >= expected {
+		t.Errorf("Expected a zero year to lower the PIA below $%.2f, got $%.2f", expected, pia)
+	}
+
+	// Earnings above the wage base do not count
+	ss.EarningsHistory[2000] = socialSecurityWageBase * 3
+	capped := PIAFromEarnings(ss)
+	ss.EarningsHistory[2000] = socialSecurityWageBase * 4
+	if pia := PIAFromEarnings(ss); pia != capped {
+		t.Errorf("Expected earnings above the wage base not to raise the PIA, got $%.2f vs $%.2f", pia, capped)
+	}
+}
+
+func TestEarningsPIAWarning(t *testing.T) {
+	config := createTestConfig()
+	config.SocialSecurity.EarningsHistory = map[int]float64{2000: 50000, 2001: 52000}
+	derived := PIAFromEarnings(config.SocialSecurity)
+
+	// The loader replaced a different estimated_pia from the file
+	config.SocialSecurity.SuppliedPIA = 2800
+	config.SocialSecurity.EstimatedPIA = derived
+	if !containsWarning(NewCalculator(config).generateWarnings(), "computed from earnings_history") {
+		t.Error("Expected a warning when estimated_pia and earnings_history disagree")
+	}
+
+	// Omitting estimated_pia, or writing back the derived value, is not a conflict
+	for _, supplied := range []float64{0, derived} {
+		config.SocialSecurity.SuppliedPIA = supplied
+		if containsWarning(NewCalculator(config).generateWarnings(), "computed from earnings_history") {
+			t.Errorf("Did not expect a warning for a supplied estimated_pia of %.2f", supplied)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package calc
+
+import (
+	"errors"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// Plan check statuses, from best to worst
+const (
+	CheckPass = "PASS"
+	CheckWarn = "WARN"
+	CheckFail = "FAIL"
+)
+
+// CheckPlan runs the eligibility rules and warnings without generating projections
+// The plan fails if retirement eligibility is not met and warns if there are any warnings.
+func (c *Calculator) CheckPlan() (models.PlanCheck, error) {
+	check := models.PlanCheck{
+		Eligible:      c.CheckRetirementEligibility(),
+		RetirementAge: c.calculateAgeAtRetirement(),
+		Warnings:      c.generateWarnings(),
+	}
+
+	pension, err := c.calculatePension()
+	switch {
+	case errors.Is(err, ErrIneligible):
+		check.Eligible = false
+	case err != nil:
+		return models.PlanCheck{}, err
+	default:
+		check.PensionReductionPct = pension.ReductionPercent
+	}
+
+	switch {
+	case !check.Eligible:
+		check.Status = CheckFail
+	case len(check.Warnings) > 0:
+		check.Status = CheckWarn
+	default:
+		check.Status = CheckPass
+	}
+	return check, nil
+}
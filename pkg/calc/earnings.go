@@ -0,0 +1,53 @@
+package calc
+
+import (
+	"math"
+	"sort"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// Social Security PIA formula values for 2025, the year earnings are indexed to
+const (
+	earningsIndexYear       = 2025
+	piaFirstBendPoint       = 1226
+	piaSecondBendPoint      = 7391
+	wageGrowthRate          = 0.035 // Assumed average wage index growth for indexing earnings
+	defaultComputationYears = 35
+)
+
+// PIAFromEarnings computes the monthly PIA in 2025 dollars from an earnings
+// history: each year's earnings are indexed to 2025 wage levels at the assumed wage
+// growth and capped at the Social Security wage base, the highest computation years (35
+// unless set) are averaged over their months to give the AIME, and the bend-point formula
+// takes 90%, 32%, and 15% of the AIME between the bend points. Missing years count as zero.
+func PIAFromEarnings(ss models.SocialSecurityInfo) float64 {
+	years := ss.ComputationYears
+	if years == 0 {
+		years = defaultComputationYears
+	}
+
+	indexed := make([]float64, 0, len(ss.EarningsHistory))
+	for year, earnings := range ss.EarningsHistory {
+		factor := math.Pow(1+wageGrowthRate, float64(earningsIndexYear-year))
+		indexed = append(indexed, math.Min(earnings*factor, socialSecurityWageBase))
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(indexed)))
+
+	var total float64
+	for i := 0; i < years && i < len(indexed); i++ {
+		total += indexed[i]
+	}
+	aime := math.Floor(total / float64(years*12))
+
+	pia := 0.90 * math.Min(aime, piaFirstBendPoint)
+	if aime > piaFirstBendPoint {
+		pia += 0.32 * (math.Min(aime, piaSecondBendPoint) - piaFirstBendPoint)
+	}
+	if aime > piaSecondBendPoint {
+		pia += 0.15 * (aime - piaSecondBendPoint)
+	}
+
+	// SSA rounds the PIA down to the dime
+	return math.Floor(pia*10) / 10
+}
@@ -0,0 +1,194 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// retirementRule is an immediate retirement rule: the minimum age in months and service
+type retirementRule struct {
+	name      string
+	kind      string
+	ageMonths int
+	service   float64
+}
+
+// immediateRetirementRules returns the immediate retirement rules for the retirement system
+// The MRA rules use the exact MRA, e.g. 56 and 6 months.
+func (c *Calculator) immediateRetirementRules() []retirementRule {
+	var rules []retirementRule
+	if c.config.Personal.RetirementSystem == "FERS" {
+		mra := c.calculateMRAMonths()
+		rules = []retirementRule{
+			{"MRA+30", "immediate_unreduced", mra, 30},
+			{"Age 60 with 20 years", "immediate_unreduced", 60 * 12, 20},
+			{"Age 62 with 5 years", "immediate_unreduced", 62 * 12, 5},
+			{"MRA+10 (reduced)", "immediate_reduced", mra, 10},
+		}
+	} else {
+		rules = []retirementRule{
+			{"Age 55 with 30 years", "immediate_unreduced", 55 * 12, 30},
+			{"Age 60 with 20 years", "immediate_unreduced", 60 * 12, 20},
+			{"Age 62 with 5 years", "immediate_unreduced", 62 * 12, 5},
+		}
+	}
+	if c.config.Personal.SpecialProvision {
+		rules = append(rules,
+			retirementRule{"Special provision at age 50 with 20 years", "immediate_unreduced", specialProvisionAge * 12, specialProvisionService},
+			retirementRule{"Special provision with 25 years", "immediate_unreduced", 0, specialProvisionAnyAgeService})
+	}
+	return rules
+}
+
+// meetsRetirementRule reports whether an age in months and service satisfy any immediate rule
+func (c *Calculator) meetsRetirementRule(ageMonths int, service float64) bool {
+	for _, rule := range c.immediateRetirementRules() {
+		if ageMonths >= rule.ageMonths && service >= rule.service {
+			return true
+		}
+	}
+	return false
+}
+
+// FindEligibilityMilestones reports the earliest date each retirement eligibility rule is met
+// Service keeps accruing from the hire date, plus any bought-back military service and
+// redeposited refunded service.
+func (c *Calculator) FindEligibilityMilestones() (models.EligibilityReport, error) {
+	birth := c.config.Personal.BirthDate
+	hire := c.config.Employment.HireDate
+	if birth.IsZero() || hire.IsZero() {
+		return models.EligibilityReport{}, fmt.Errorf("birth date and hire date are required to find eligibility milestones")
+	}
+
+	report := models.EligibilityReport{
+		RetirementSystem: c.config.Personal.RetirementSystem,
+		BirthDate:        birth,
+		HireDate:         hire,
+	}
+
+	ageDate := func(years int) time.Time { return birth.AddDate(years, 0, 0) }
+	add := func(name, kind string, date time.Time) {
+		report.Milestones = append(report.Milestones, c.newMilestone(name, kind, date))
+	}
+	addDeferred := func(name string, separation, start time.Time) {
+		// A separation on or after the start date is an immediate retirement, not a deferred one
+		if !separation.Before(start) {
+			return
+		}
+		milestone := c.newMilestone(name, "deferred", start)
+		milestone.SeparationDate = separation
+		milestone.ServiceYears = c.eligibilityServiceAt(separation)
+		report.Milestones = append(report.Milestones, milestone)
+	}
+
+	for _, rule := range c.immediateRetirementRules() {
+		add(rule.name, rule.kind, laterOf(birth.AddDate(0, rule.ageMonths, 0), c.serviceReachedDate(rule.service)))
+	}
+	if c.config.Personal.RetirementSystem == "FERS" {
+		addDeferred("Deferred at MRA with 10 years (reduced)", c.serviceReachedDate(10), birth.AddDate(0, c.calculateMRAMonths(), 0))
+	}
+	addDeferred("Deferred at age 62 with 5 years", c.serviceReachedDate(5), ageDate(62))
+
+	sort.SliceStable(report.Milestones, func(i, j int) bool {
+		return report.Milestones[i].Date.Before(report.Milestones[j].Date)
+	})
+
+	return report, nil
+}
+
+// newMilestone builds a milestone with the age and service on its date
+func (c *Calculator) newMilestone(name, kind string, date time.Time) models.EligibilityMilestone {
+	months := monthsBetween(c.config.Personal.BirthDate, date)
+	return models.EligibilityMilestone{
+		Name:         name,
+		Type:         kind,
+		Date:         date,
+		AgeYears:     months / 12,
+		AgeMonths:    months % 12,
+		ServiceYears: c.eligibilityServiceAt(date),
+	}
+}
+
+// priorServiceCredit returns service credited toward eligibility beyond the current employment:
+// bought-back military service and redeposited refunded service
+func (c *Calculator) priorServiceCredit() float64 {
+	return c.militaryCreditYears() + c.redepositedServiceYears()
+}
+
+// militaryCreditYears returns bought-back military service, which counts toward eligibility
+func (c *Calculator) militaryCreditYears() float64 {
+	military := c.config.Employment.CreditableService.MilitaryService
+	if military == nil || !military.BoughtBack {
+		return 0
+	}
+	return military.Years
+}
+
+// eligibilityServiceAt returns the service counted toward eligibility on a date
+func (c *Calculator) eligibilityServiceAt(date time.Time) float64 {
+	return ServiceYears(c.config.Employment.HireDate, date) + c.priorServiceCredit()
+}
+
+// serviceReachedDate returns the date eligibility service first reaches years
+func (c *Calculator) serviceReachedDate(years float64) time.Time {
+	hire := c.config.Employment.HireDate
+	civilian := years - c.priorServiceCredit()
+	if civilian <= 0 {
+		return hire
+	}
+
+	// Invert ServiceYears: whole years, then 12-month years, then 30-day months
+	whole := math.Floor(civilian)
+	months := math.Floor((civilian - whole) * 12)
+	days := math.Ceil(((civilian-whole)*12 - months) * 30)
+	return hire.AddDate(int(whole), int(months), int(days))
+}
+
+// ServiceYears returns the service between two dates in years
+// It uses OPM's convention of 30-day months and 12-month years for the calendar duration.
+func ServiceYears(start, end time.Time) float64 {
+	years, months, days := ServiceDuration(start, end)
+	return float64(years) + float64(months)/12 + float64(days)/360
+}
+
+// ServiceDuration calculates the calendar years, months, and days between two dates
+// by subtracting the dates field by field, borrowing 30 days for a month as OPM does
+func ServiceDuration(start, end time.Time) (years, months, days int) {
+	if end.Before(start) {
+		return 0, 0, 0
+	}
+
+	years = end.Year() - start.Year()
+	months = int(end.Month()) - int(start.Month())
+	days = end.Day() - start.Day()
+	if days < 0 {
+		days += 30
+		months--
+	}
+	if months < 0 {
+		months += 12
+		years--
+	}
+	return years, months, days
+}
+
+// monthsBetween returns the whole months elapsed from start to end
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	return months
+}
+
+// laterOf returns the later of two dates
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
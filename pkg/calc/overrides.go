@@ -0,0 +1,167 @@
+package calc
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"rgehrsitz/ferex_cli/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ScenarioOverride changes one dimension of a config to form a comparison scenario
+// Name selects a registered override such as "age" or "claiming_age"; Value is parsed by it.
+type ScenarioOverride struct {
+	Name  string
+	Value string
+}
+
+// scenarioOverrides maps each known override name to the function that applies it
+var scenarioOverrides = map[string]func(*models.Config, string) error{
+	"age":          applyRetirementAgeOverride,
+	"strategy":     applyStrategyOverride,
+	"claiming_age": applyClaimingAgeOverride,
+	"growth_rate":  applyGrowthRateOverride,
+}
+
+// Apply applies the override to config, which should be a copy of the base config
+func (o ScenarioOverride) Apply(config *models.Config) error {
+	apply, ok := scenarioOverrides[o.Name]
+	if !ok {
+		return fmt.Errorf("unknown scenario override: %s", o.Name)
+	}
+	if err := apply(config, o.Value); err != nil {
+		return fmt.Errorf("invalid %s override %q: %w", o.Name, o.Value, err)
+	}
+	return nil
+}
+
+// applyScenarioOverrides returns a copy of base with each override applied in order
+func applyScenarioOverrides(base *models.Config, overrides []ScenarioOverride) (*models.Config, error) {
+	config := *base
+	for _, override := range overrides {
+		if err := override.Apply(&config); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// compareScenarios calculates one scenario per override set and compares the results
+func compareScenarios(base *models.Config, scenarios [][]ScenarioOverride) (*models.ComparisonResults, error) {
+	configs := make([]*models.Config, 0, len(scenarios))
+	for _, overrides := range scenarios {
+		config, err := applyScenarioOverrides(base, overrides)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+
+	results, err := calculateScenarios(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &models.ComparisonResults{
+		Scenarios:         results,
+		ComparisonMetrics: calculateComparisonMetrics(results),
+	}
+	if err := SetComparisonBaseline(comparison, 0); err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
+
+// SetComparisonBaseline measures every scenario's deltas against the scenario at index baseline
+func SetComparisonBaseline(comparison *models.ComparisonResults, baseline int) error {
+	if baseline < 0 || baseline >= len(comparison.Scenarios) {
+		return fmt.Errorf("baseline scenario %d out of range", baseline+1)
+	}
+
+	base := comparison.Scenarios[baseline].Summary
+	comparison.Baseline = baseline
+	comparison.Deltas = make([]models.ScenarioDelta, len(comparison.Scenarios))
+	for i, scenario := range comparison.Scenarios {
+		summary := scenario.Summary
+		delta := models.ScenarioDelta{LifetimeIncome: summary.LifetimeIncome - base.LifetimeIncome}
+		if summary.TSPProjectedDepletion > 0 && base.TSPProjectedDepletion > 0 {
+			years := summary.TSPProjectedDepletion - base.TSPProjectedDepletion
+			delta.TSPDepletionDelta = &years
+		}
+		comparison.Deltas[i] = delta
+	}
+	return nil
+}
+
+// applyRetirementAgeOverride retires on the birthday reaching the given age
+// Service accrues up to the new retirement date, so the multiplier and eligibility
+// reflect working longer or leaving earlier.
+func applyRetirementAgeOverride(config *models.Config, value string) error {
+	age, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	birth := config.Personal.BirthDate
+	retirementDate := time.Date(birth.Year()+age, birth.Month(), birth.Day(), 0, 0, 0, 0, time.UTC)
+
+	hire := config.Employment.HireDate
+	shift := ServiceYears(hire, retirementDate) - ServiceYears(hire, config.Retirement.TargetRetirementDate)
+	config.Employment.CreditableService.TotalYears += shift
+	config.Retirement.TargetRetirementDate = retirementDate
+	return nil
+}
+
+// overrideValidator checks overridden fields against the config's own validation tags, so
+// the accepted values are defined once, on the config
+var overrideValidator = validator.New()
+
+// applyStrategyOverride switches the TSP withdrawal strategy
+func applyStrategyOverride(config *models.Config, value string) error {
+	tsp := config.TSP
+	tsp.WithdrawalStrategy = value
+	if err := overrideValidator.StructPartial(tsp, "WithdrawalStrategy"); err != nil {
+		return fmt.Errorf("unknown withdrawal strategy")
+	}
+	if value == "custom_schedule" && len(config.TSP.WithdrawalSchedule) == 0 {
+		return fmt.Errorf("custom_schedule strategy requires a withdrawal_schedule")
+	}
+
+	config.TSP.WithdrawalStrategy = value
+	if value == "percentage" && config.TSP.WithdrawalRate == 0 {
+		config.TSP.WithdrawalRate = 0.04
+	}
+	// The gross-up and withdrawal COLA only apply to a fixed_amount withdrawal
+	if value != "fixed_amount" {
+		config.TSP.WithdrawalBasis = ""
+		config.TSP.WithdrawalCOLA = 0
+	}
+	return nil
+}
+
+// applyClaimingAgeOverride changes the Social Security claiming age
+func applyClaimingAgeOverride(config *models.Config, value string) error {
+	age, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if age < 62 || age > 70 {
+		return fmt.Errorf("claiming age must be between 62 and 70")
+	}
+
+	config.SocialSecurity.ClaimingAge = age
+	return nil
+}
+
+// applyGrowthRateOverride changes the assumed TSP growth rate
+func applyGrowthRateOverride(config *models.Config, value string) error {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+
+	config.TSP.GrowthRate = models.Rate(rate)
+	return nil
+}
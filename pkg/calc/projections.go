@@ -7,19 +7,25 @@ import (
 	"rgehrsitz/ferex_cli/internal/models"
 )
 
+// projectionEndAge is the age through which annual projections run
+const projectionEndAge = 95
+
 // generateAnnualProjections creates year-by-year projections
 func (c *Calculator) generateAnnualProjections(pension models.PensionCalculation, ss models.SocialSecurityCalculation, fersup models.FERSSupplementCalculation) ([]models.AnnualProjection, error) {
 	var projections []models.AnnualProjection
 	
 	startAge := c.calculateAgeAtRetirement()
-	endAge := 95 // Project to age 95
+	endAge := projectionEndAge
 	
 	// Initialize TSP balance (traditional + roth)
 	tspBalance := c.config.TSP.TraditionalBalance + c.config.TSP.RothBalance
+	traditionalBalance := c.traditionalTSPBalance()
+	cashBalance := c.config.CashReserve.Balance
+	hsaBalance := c.config.HSA.Balance
 	
-	for age := startAge; age <= endAge; age++ {
-		currentAge := time.Now().Year() - c.config.Personal.BirthDate.Year()
-		year := time.Now().Year() + (age - currentAge)
+	for age := c.projectionStartAge(); age <= endAge; age++ {
+		currentAge := c.now.Year() - c.config.Personal.BirthDate.Year()
+		year := c.now.Year() + (age - currentAge)
 		
 		projection := models.AnnualProjection{
 			Year:             year,
@@ -28,45 +34,115 @@ func (c *Calculator) generateAnnualProjections(pension models.PensionCalculation
 		}
 		
 		// Calculate income sources
-		projection.PensionIncome = c.calculatePensionIncome(pension, age, startAge)
+		projection.RetireeDeceased = c.isDeceased(age)
+		projection.PensionIncome = c.calculatePensionIncome(pension, age, c.commencementAge(startAge))
 		projection.FERSSupplementIncome = c.calculateFERSSupplementIncome(fersup, age)
 		projection.SocialSecurityIncome = c.calculateSSIncome(ss, age)
+		projection.SpousalSSIncome = c.calculateSpousalSSIncome(ss, age)
+		projection.SalaryIncome = c.calculateSalaryIncome(age, startAge)
+		projection.TSPContribution = projection.SalaryIncome * c.config.Employment.TSPContributionRate
+		if age == startAge {
+			projection.ContributionRefund = pension.ExcessContributionRefund
+			projection.AnnualLeavePayout = c.calculateAnnualLeavePayout()
+		}
+		projection.OtherPensionIncome, projection.TaxableOtherPension = c.calculateOtherPensionIncome(age)
+		projection.IncomeGap = ss.MonthlyBenefit > 0 && age < ss.ClaimingAge &&
+			projection.FERSSupplementIncome == 0 && !projection.RetireeDeceased
 		
 		// Calculate TSP withdrawal
-		projection.TSPWithdrawal = c.calculateTSPWithdrawal(tspBalance, age)
+		projection.TSPReturn = c.tspReturnRate(age - startAge)
+		if !projection.RetireeDeceased {
+			projection.RMD = c.traditionalRMD(traditionalBalance, age)
+		}
+		withdrawal := c.calculateTSPWithdrawal(tspBalance, traditionalBalance, age)
+		if c.config.TSP.WithdrawalBasis == "net" {
+			withdrawal = c.grossUpWithdrawal(projection, age, tspBalance, traditionalBalance)
+		}
 		
-		// Update TSP balance
-		tspGrowth := tspBalance * c.config.TSP.GrowthRate
-		tspBalance = tspBalance + tspGrowth - projection.TSPWithdrawal
+		// Spend the cash reserve instead of selling TSP in a down year; RMDs still come from the TSP
+		if projection.TSPReturn < 0 && cashBalance > 0 {
+			projection.CashWithdrawal = math.Min(cashBalance, math.Max(withdrawal-c.rmdFloor(traditionalBalance, age), 0))
+			withdrawal -= projection.CashWithdrawal
+		}
+		projection.TSPWithdrawal = withdrawal
+		if c.config.TSP.WithdrawalSource == "bracket_fill" {
+			projection.TaxableTSPWithdrawal = c.calculateBracketFillTaxable(projection, age, traditionalBalance, tspBalance-traditionalBalance)
+		} else {
+			projection.TaxableTSPWithdrawal = c.calculateTaxableTSPWithdrawal(projection.TSPWithdrawal, tspBalance, traditionalBalance)
+		}
+		
+		// Update TSP balance, tracking the Traditional part separately for bracket_fill
+		offset := c.withdrawalGrowthOffset()
+		tspGrowth := (tspBalance - projection.TSPWithdrawal*offset) * projection.TSPReturn
+		tspBalance = tspBalance + tspGrowth - projection.TSPWithdrawal + projection.TSPContribution
+		traditionalGrowth := (traditionalBalance - projection.TaxableTSPWithdrawal*offset) * projection.TSPReturn
+		traditionalBalance += traditionalGrowth - projection.TaxableTSPWithdrawal + projection.TSPContribution
 		if tspBalance < 0 {
 			tspBalance = 0
 		}
 		
+		// Update the cash reserve, refilling it from the TSP after a good year; the Traditional
+		// share of a refill is taxed as it leaves the TSP, so spending the cash later is not
+		cashBalance = (cashBalance - projection.CashWithdrawal) * (1 + c.config.CashReserve.InterestRate)
+		if target := c.config.CashReserve.Balance; projection.TSPReturn > 0 && cashBalance < target {
+			projection.CashRefill = math.Min(target-cashBalance, tspBalance)
+			if tspBalance > 0 {
+				projection.TaxableCashRefill = projection.CashRefill * traditionalBalance / tspBalance
+				traditionalBalance -= projection.TaxableCashRefill
+			}
+			tspBalance -= projection.CashRefill
+			cashBalance += projection.CashRefill
+		}
+		traditionalBalance = math.Max(math.Min(traditionalBalance, tspBalance), 0)
+		
+		// In-plan Roth conversions move Traditional money to Roth, leaving the total unchanged
+		projection.TSPRothConversion, projection.IRARothConversion = c.calculateRothConversions(age, traditionalBalance)
+		traditionalBalance -= projection.TSPRothConversion
+		
 		projection.TSPGrowth = tspGrowth
 		projection.TSPEndBalance = tspBalance
+		projection.CashEndBalance = cashBalance
 		
 		// Calculate gross income
-		projection.GrossIncome = projection.PensionIncome + 
-			projection.FERSSupplementIncome + 
-			projection.SocialSecurityIncome + 
-			projection.TSPWithdrawal
+		projection.GrossIncome = calculateGrossIncome(projection)
 		
 		// Calculate taxes and deductions
 		projection.FederalTax = c.calculateFederalTax(projection, age)
+		projection.EffectiveTaxRate, projection.MarginalTaxRate = c.calculateTaxRates(projection, age)
+		projection.TopBracketRate = calculateTopBracketRate(c.calculateFederalTaxableIncome(projection, age)/c.taxIndexFactor(age), c.filingSchedule(projection))
+		if n := len(projections); n > 0 && projection.TopBracketRate > projections[n-1].TopBracketRate {
+			projection.BracketCrossing = true
+		}
+		projection.MAGI = c.calculateMAGI(projection)
+		projection.IRMAATier = calculateIRMAATier(projection.MAGI, age, c.filingSchedule(projection))
 		projection.StateTax = c.calculateStateTax(projection, age)
 		projection.HealthInsurance = c.calculateHealthInsurance(age)
 		projection.LifeInsurance = c.calculateLifeInsurance(age)
 		
+		// The HSA pays premiums tax-free, so it offsets the deduction without adding income
+		projection.HSAWithdrawal = c.calculateHSAWithdrawal(projection.HealthInsurance, hsaBalance, age)
+		hsaBalance = (hsaBalance - projection.HSAWithdrawal) * (1 + c.config.HSA.GrowthRate)
+		projection.HSAEndBalance = hsaBalance
+		
 		projection.TotalDeductions = projection.FederalTax + 
 			projection.StateTax + 
-			projection.HealthInsurance + 
-			projection.LifeInsurance
+			projection.HealthInsurance - projection.HSAWithdrawal +
+			projection.LifeInsurance +
+			projection.TSPContribution
 		
 		projection.NetIncome = projection.GrossIncome - projection.TotalDeductions
 		
+		// Compare net income with spending needs
+		projection.RequiredSpending = c.calculateRequiredSpending(age, startAge)
+		projection.OneTimeExpenses = c.calculateOneTimeExpenses(age)
+		if need := projection.RequiredSpending + projection.OneTimeExpenses; need > 0 {
+			projection.Surplus = projection.NetIncome - need
+			projection.Shortfall = math.Max(-projection.Surplus, 0)
+		}
+		
 		// Apply COLA
 		projection.COLARate = c.calculateCOLA(age, startAge)
-		projection.InflationRate = 0.025 // 2.5% default inflation
+		projection.InflationRate = c.inflationRate()
 		
 		projections = append(projections, projection)
 	}
@@ -74,8 +150,27 @@ func (c *Calculator) generateAnnualProjections(pension models.PensionCalculation
 	return projections, nil
 }
 
+// calculateGrossIncome totals every income source in a projection year
+// The CSRS excess-contribution refund is left out: it returns contributions made from
+// after-tax pay, so it is reported on its own rather than as income.
+func calculateGrossIncome(projection models.AnnualProjection) float64 {
+	return projection.PensionIncome + 
+		projection.FERSSupplementIncome + 
+		projection.SocialSecurityIncome + 
+		projection.SpousalSSIncome +
+		projection.TSPWithdrawal +
+		projection.CashWithdrawal +
+		projection.SalaryIncome +
+		projection.AnnualLeavePayout +
+		projection.OtherPensionIncome
+}
+
 // calculatePensionIncome calculates annual pension income with COLA
 func (c *Calculator) calculatePensionIncome(pension models.PensionCalculation, currentAge, startAge int) float64 {
+	if c.isDeceased(currentAge) {
+		return c.calculateSurvivorAnnuityIncome(pension, currentAge, startAge)
+	}
+	
 	basePension := pension.FinalPension
 	
 	// Apply COLA adjustments
@@ -84,36 +179,198 @@ func (c *Calculator) calculatePensionIncome(pension models.PensionCalculation, c
 		return 0 // Not yet retired
 	}
 	
+	// Phased retirement pays the partial annuity without COLA until full retirement
+	if phased := c.config.Retirement.PhasedRetirement; phased != nil {
+		if yearsRetired < phased.Years {
+			return pension.PhasedPension
+		}
+		basePension = pension.CompositePension
+		yearsRetired -= phased.Years
+	}
+	
 	// First year of retirement - no COLA yet
 	if yearsRetired == 0 {
 		return basePension
 	}
 	
-	// FERS COLA eligibility - most FERS retirees don't get COLA until 62
-	if c.config.Personal.RetirementSystem == "FERS" && currentAge < 62 {
-		return basePension
+	// Apply compound COLA for subsequent years; CSRS gets the full rate at any age, including
+	// a transferee's CSRS component
+	return basePension * c.annuityCOLAGrowth(pension.CSRSShare, yearsRetired, c.fersCOLAYears(yearsRetired, currentAge))
+}
+
+// fersCOLAYears returns how many of the years since the annuity began earned a FERS COLA
+// Most FERS retirees don't get COLA until 62; the first one is paid at 62, so only the
+// years from 61 on count. Special provision retirees get COLAs from the start.
+func (c *Calculator) fersCOLAYears(years, currentAge int) int {
+	if c.config.Personal.RetirementSystem != "FERS" || c.config.Personal.SpecialProvision {
+		return years
+	}
+	return max(min(years, currentAge-(fersCOLAStartAge-1)), 0)
+}
+
+// annuityCOLAGrowth returns the growth of an annuity from its COLAs, blending a transferee's
+// CSRS component, which receives the full rate for csrsYears, with the rest of the annuity,
+// which receives the annuity's own rate for colaYears
+func (c *Calculator) annuityCOLAGrowth(csrsShare float64, csrsYears, colaYears int) float64 {
+	growth := math.Pow(1+c.annuityCOLARate(), float64(colaYears))
+	if csrsShare <= 0 {
+		return growth
+	}
+	return csrsShare*math.Pow(1+c.colaRate(), float64(csrsYears)) + (1-csrsShare)*growth
+}
+
+// fersCOLAStartAge is the age FERS retirees, other than special provision retirees, first receive COLAs
+const fersCOLAStartAge = 62
+
+// isDeceased reports whether the retiree's modeled death has occurred by an age
+func (c *Calculator) isDeceased(age int) bool {
+	deathAge := c.config.Personal.AssumedDeathAge
+	return deathAge > 0 && age >= deathAge
+}
+
+// calculateSurvivorAnnuityIncome calculates the survivor annuity paid after the retiree's death
+// The survivor annuity shares every COLA granted since commencement, and survivor annuitants
+// receive COLAs regardless of age: FERS at the diet rate, CSRS (and a transferee's CSRS
+// component) at the full rate.
+func (c *Calculator) calculateSurvivorAnnuityIncome(pension models.PensionCalculation, currentAge, startAge int) float64 {
+	survivorAnnuity := c.calculateSurvivorAnnuity(pension)
+	if survivorAnnuity <= 0 || currentAge < startAge {
+		return 0
 	}
 	
-	// Apply compound COLA for subsequent years
-	colaRate := 0.025 // 2.5% average
-	if c.config.Personal.RetirementSystem == "FERS" {
-		colaRate = c.calculateFERSCOLA(colaRate)
+	// COLAs accrue on the retiree's age as they would have on the annuity itself
+	years := currentAge - startAge
+	return survivorAnnuity * c.annuityCOLAGrowth(pension.CSRSShare, years, c.fersCOLAYears(years, currentAge))
+}
+
+// isAlreadyRetired reports whether the retirement date is before the evaluation date
+func (c *Calculator) isAlreadyRetired() bool {
+	return c.config.Retirement.TargetRetirementDate.Before(c.now)
+}
+
+// projectionStartAge returns the first projected age: the retirement age, or this year's age
+// when the retirement date has passed, so the entered balances are treated as today's and
+// income is projected from now with the COLAs granted since retirement
+func (c *Calculator) projectionStartAge() int {
+	startAge := c.calculateAgeAtRetirement()
+	if !c.isAlreadyRetired() {
+		return startAge
+	}
+	if currentAge := c.now.Year() - c.config.Personal.BirthDate.Year(); currentAge > startAge {
+		return currentAge
+	}
+	return startAge
+}
+
+// calculateSalaryIncome calculates part-time salary earned during phased retirement
+func (c *Calculator) calculateSalaryIncome(currentAge, startAge int) float64 {
+	phased := c.config.Retirement.PhasedRetirement
+	if phased == nil || currentAge < startAge || currentAge >= startAge+phased.Years || c.isDeceased(currentAge) {
+		return 0
+	}
+	return phased.PartTimeSalary
+}
+
+// workHoursPerYear is OPM's standard divisor for converting annual pay to an hourly rate
+const workHoursPerYear = 2087
+
+// calculateAnnualLeavePayout calculates the lump sum paid for unused annual leave at retirement
+// The hourly rate comes from the final salary, or the high-3 when it is not configured.
+func (c *Calculator) calculateAnnualLeavePayout() float64 {
+	salary := c.config.Employment.FinalSalary
+	if salary == 0 {
+		salary = c.high3Salary()
+	}
+	return c.config.Employment.UnusedAnnualLeaveHours * salary / workHoursPerYear
+}
+
+// isFederallyEmployed reports whether the retiree still works for the government at an age,
+// as a phased retiree does until full retirement
+func (c *Calculator) isFederallyEmployed(currentAge int) bool {
+	phased := c.config.Retirement.PhasedRetirement
+	startAge := c.calculateAgeAtRetirement()
+	return phased != nil && currentAge >= startAge && currentAge < startAge+phased.Years
+}
+
+// calculateRothConversions totals the year's planned Roth conversions by source
+// In-plan TSP conversions are limited to the Traditional balance.
+func (c *Calculator) calculateRothConversions(age int, traditionalBalance float64) (float64, float64) {
+	var tsp, ira float64
+	for _, conversion := range c.config.RothConversions {
+		if conversion.Age != age {
+			continue
+		}
+		if conversion.Source == "tsp" {
+			tsp += conversion.Amount
+		} else {
+			ira += conversion.Amount
+		}
+	}
+	return math.Min(tsp, traditionalBalance), ira
+}
+
+// calculateOtherPensionIncome calculates non-federal pension income and its taxable portion
+func (c *Calculator) calculateOtherPensionIncome(currentAge int) (float64, float64) {
+	if c.isDeceased(currentAge) {
+		return 0, 0
 	}
 	
-	return basePension * math.Pow(1+colaRate, float64(yearsRetired))
+	var total, taxable float64
+	for _, pension := range c.config.OtherPensions {
+		if currentAge < pension.StartAge {
+			continue
+		}
+		amount := pension.AnnualAmount * math.Pow(1+pension.COLA, float64(currentAge-pension.StartAge))
+		total += amount
+		if !pension.TaxExempt {
+			taxable += amount
+		}
+	}
+	return total, taxable
 }
 
 // calculateFERSSupplementIncome calculates FERS Supplement income
 func (c *Calculator) calculateFERSSupplementIncome(fersup models.FERSSupplementCalculation, currentAge int) float64 {
-	if !fersup.Eligible || currentAge < fersup.StartAge || currentAge >= fersup.EndAge {
+	if !fersup.Eligible || currentAge < fersup.StartAge || currentAge >= fersup.EndAge || c.isDeceased(currentAge) {
+		return 0
+	}
+	
+	// The supplement is suspended while still federally employed
+	if c.isFederallyEmployed(currentAge) {
 		return 0
 	}
 	
-	return fersup.MonthlyAmount * 12
+	// The earnings test takes $1 of supplement for every $2 earned over the annual limit,
+	// which grows with the brackets when they are indexed
+	supplement := fersup.MonthlyAmount * 12
+	if c.supplementEarningsTestApplies(currentAge) {
+		limit := supplementEarningsLimit * c.taxIndexFactor(currentAge)
+		excess := math.Max(c.config.Retirement.SupplementEarnings-limit, 0)
+		supplement = math.Max(supplement-excess/2, 0)
+	}
+	return supplement
+}
+
+// supplementEarningsLimit is the 2025 Social Security annual exempt amount used by the
+// FERS supplement's earnings test
+const supplementEarningsLimit = 23400.0
+
+// supplementEarningsTestApplies reports whether the earnings test reduces the supplement
+// at an age. Special provision retirees are exempt until they reach their MRA; everyone
+// else is tested from the first supplement payment.
+func (c *Calculator) supplementEarningsTestApplies(currentAge int) bool {
+	if !c.config.Personal.SpecialProvision {
+		return true
+	}
+	return currentAge >= c.calculateMRA()
 }
 
 // calculateSSIncome calculates Social Security income
 func (c *Calculator) calculateSSIncome(ss models.SocialSecurityCalculation, currentAge int) float64 {
+	cut := c.ssTrustFundFactor(currentAge)
+	if c.isDeceased(currentAge) {
+		return c.calculateSurvivorSSIncome(ss, currentAge) * cut
+	}
 	if currentAge < ss.ClaimingAge {
 		return 0
 	}
@@ -121,24 +378,275 @@ func (c *Calculator) calculateSSIncome(ss models.SocialSecurityCalculation, curr
 	// Apply COLA adjustments
 	yearsReceiving := currentAge - ss.ClaimingAge
 	if yearsReceiving <= 0 {
-		return ss.MonthlyBenefit * 12
+		return ss.MonthlyBenefit * 12 * cut
 	}
 	
 	// Apply compound COLA (typically similar to general inflation)
-	colaRate := 0.025 // 2.5% average
-	return ss.MonthlyBenefit * 12 * math.Pow(1+colaRate, float64(yearsReceiving))
+	colaRate := c.colaRate()
+	return ss.MonthlyBenefit * 12 * math.Pow(1+colaRate, float64(yearsReceiving)) * cut
+}
+
+// ssTrustFundFactor returns the share of scheduled Social Security benefits paid at an age
+// A configured trust fund shortfall cuts every benefit by the haircut from its year onward.
+func (c *Calculator) ssTrustFundFactor(age int) float64 {
+	cutYear := c.config.SocialSecurity.TrustFundCutYear
+	if cutYear == 0 || c.config.Personal.BirthDate.Year()+age < cutYear {
+		return 1
+	}
+	return 1 - c.config.SocialSecurity.TrustFundCut
+}
+
+// survivorSSMinimumFactor is the share of the deceased's benefit paid to a survivor claiming at 60
+const survivorSSMinimumFactor = 0.715
+
+// calculateSurvivorSSIncome calculates widow(er) Social Security paid after the retiree's death
+// The survivor receives the retiree's benefit (the PIA if the retiree died before claiming),
+// reduced linearly from 100% at full retirement age to 71.5% when claimed at 60. In the year
+// the survivor reaches the claiming age, benefits are paid from their birthday month.
+func (c *Calculator) calculateSurvivorSSIncome(ss models.SocialSecurityCalculation, currentAge int) float64 {
+	claimAge := c.config.SocialSecurity.SurvivorSSClaimingAge
+	if claimAge == 0 {
+		return 0
+	}
+	
+	spouseAge := c.spouseAge(currentAge)
+	if spouseAge < claimAge {
+		return 0
+	}
+	months := 12.0
+	if spouseAge == claimAge {
+		months = float64(13 - int(c.spouseBirthDate().Month()))
+	}
+	
+	colaRate := c.colaRate()
+	deathAge := c.config.Personal.AssumedDeathAge
+	benefit := c.config.SocialSecurity.EstimatedPIA * 12 * math.Pow(1+colaRate, float64(currentAge-deathAge))
+	if deathAge > ss.ClaimingAge {
+		benefit = ss.MonthlyBenefit * 12 * math.Pow(1+colaRate, float64(currentAge-ss.ClaimingAge))
+	}
+	
+	factor := 1.0
+	if claimAge < ssFullRetirementAge {
+		factor = survivorSSMinimumFactor +
+			(1-survivorSSMinimumFactor)*float64(claimAge-60)/float64(ssFullRetirementAge-60)
+	}
+	return benefit * factor * months / 12
+}
+
+// spouseAge converts a retiree age in the projection to the spouse's age that year
+// Without a spouse birth date the spouse is assumed to be the retiree's age.
+func (c *Calculator) spouseAge(retireeAge int) int {
+	return retireeAge + c.config.Personal.BirthDate.Year() - c.spouseBirthDate().Year()
+}
+
+// spouseBirthDate returns the spouse's birth date, or the retiree's when none is set
+func (c *Calculator) spouseBirthDate() time.Time {
+	if spouseBirth := c.config.Personal.SpouseBirthDate; !spouseBirth.IsZero() {
+		return spouseBirth
+	}
+	return c.config.Personal.BirthDate
+}
+
+// spousalSSMinimumFactor is the share of the full spousal benefit paid when claimed at 62
+const spousalSSMinimumFactor = 0.65
+
+// calculateSpousalSSIncome calculates the spouse's benefit on the retiree's record
+// The spouse receives the excess of half the retiree's PIA over their own PIA, reduced for
+// claiming before full retirement age and capped by the family maximum. It is paid once both
+// have claimed and stops at the retiree's death, when the survivor benefit takes over.
+func (c *Calculator) calculateSpousalSSIncome(ss models.SocialSecurityCalculation, currentAge int) float64 {
+	spouse := c.config.SocialSecurity.SpouseBenefit
+	if spouse == nil || currentAge < ss.ClaimingAge || c.isDeceased(currentAge) || c.spouseAge(currentAge) < spouse.ClaimingAge {
+		return 0
+	}
+	
+	excess := ss.PIA*0.5 - spouse.EstimatedPIA
+	if excess <= 0 {
+		return 0
+	}
+	
+	factor := 1.0
+	if spouse.ClaimingAge < ssFullRetirementAge {
+		factor = spousalSSMinimumFactor +
+			(1-spousalSSMinimumFactor)*float64(spouse.ClaimingAge-62)/float64(ssFullRetirementAge-62)
+	}
+	
+	monthly := capAuxiliaryBenefits(ss.PIA, excess*factor)
+	return monthly * 12 * math.Pow(1+c.colaRate(), float64(currentAge-ss.ClaimingAge)) * c.ssTrustFundFactor(currentAge)
 }
 
 // calculateTSPWithdrawal calculates TSP withdrawal amount
-func (c *Calculator) calculateTSPWithdrawal(balance float64, age int) float64 {
+// The strategy amount is clamped to the configured ceiling and floor, then raised to
+// the RMD on the Traditional part of the balance when one is required, and finally
+// limited to the available balance.
+func (c *Calculator) calculateTSPWithdrawal(balance, traditional float64, age int) float64 {
 	if balance <= 0 {
 		return 0
 	}
 	
+	return c.limitWithdrawal(c.calculateStrategyWithdrawal(balance, age), balance, traditional, age)
+}
+
+// fixedWithdrawalAmount returns the fixed_amount withdrawal for an age, raised by the
+// withdrawal COLA for each year since retirement
+func (c *Calculator) fixedWithdrawalAmount(age int) float64 {
+	years := math.Max(float64(age-c.calculateAgeAtRetirement()), 0)
+	return c.config.TSP.WithdrawalAmount * math.Pow(1+c.config.TSP.WithdrawalCOLA, years)
+}
+
+// limitWithdrawal clamps a withdrawal to the ceiling and floor, raises it to the RMD floor,
+// and limits it to the balance
+func (c *Calculator) limitWithdrawal(withdrawal, balance, traditional float64, age int) float64 {
+	if ceiling := c.config.TSP.WithdrawalCeiling; ceiling > 0 {
+		withdrawal = math.Min(withdrawal, ceiling)
+	}
+	if floor := c.config.TSP.WithdrawalFloor; floor > 0 {
+		withdrawal = math.Max(withdrawal, floor)
+	}
+	
+	// RMDs are required by law, so they override the ceiling
+	withdrawal = math.Max(withdrawal, c.rmdFloor(traditional, age))
+	
+	return math.Min(withdrawal, balance)
+}
+
+// rmdFloor returns the RMD a year's withdrawal is raised to, or 0 when no RMD floor applies
+// The fixed_amount, life_expectancy, percentage, and lump_sum strategies withdraw exactly
+// their own amount unless a withdrawal floor or ceiling guardrail is set; the other
+// strategies always honor the RMD.
+func (c *Calculator) rmdFloor(traditional float64, age int) float64 {
+	tsp := c.config.TSP
+	switch {
+	case tsp.WithdrawalFloor > 0 || tsp.WithdrawalCeiling > 0:
+	case tsp.WithdrawalStrategy == "bequest" || tsp.WithdrawalStrategy == "income_only" ||
+		tsp.WithdrawalStrategy == "custom_schedule":
+	default:
+		return 0
+	}
+	return c.traditionalRMD(traditional, age)
+}
+
+// withdrawalGrowthOffset returns the share of the year's withdrawal taken before growth accrues
+func (c *Calculator) withdrawalGrowthOffset() float64 {
+	switch c.config.TSP.GrowthTiming {
+	case "begin_of_year":
+		return 1
+	case "mid_year":
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// tspReturnRate returns the TSP return for a year of retirement
+// The configured return sequence covers the first years; later years use the growth rate.
+// Either is held to the annual growth cap when one is set; losses are never capped.
+func (c *Calculator) tspReturnRate(yearsRetired int) float64 {
+	rate := c.growthRate()
+	if sequence := c.config.TSP.ReturnSequence; yearsRetired >= 0 && yearsRetired < len(sequence) {
+		rate = sequence[yearsRetired]
+	}
+	if limit := c.config.TSP.MaxAnnualGrowth; limit > 0 {
+		rate = math.Min(rate, limit)
+	}
+	return rate
+}
+
+// calculateBequestWithdrawal calculates the level withdrawal that leaves the bequest target
+// at the projection end. The target is inflated to end-of-projection dollars, and the amount
+// is re-solved each year for the remaining years so the drawdown tracks actual balances.
+func (c *Calculator) calculateBequestWithdrawal(balance float64, age int) float64 {
+	years := float64(projectionEndAge - age + 1)
+	if years <= 0 {
+		return 0
+	}
+
+	startAge := c.calculateAgeAtRetirement()
+	target := c.config.Retirement.BequestTarget * math.Pow(1+c.inflationRate(), float64(projectionEndAge-startAge+1))
+
+	// Balance after n years of growth g and end-of-year withdrawals W:
+	// B(1+g)^n - W * ((1+g)^n - 1) / g, solved for W so the result equals target
+	growth := c.growthRate()
+	compound := math.Pow(1+growth, years)
+	annuityFactor := years
+	if growth > 0 {
+		annuityFactor = (compound - 1) / growth
+	}
+
+	return math.Max((balance*compound-target)/annuityFactor, 0)
+}
+
+// calculateTaxableTSPWithdrawal returns the taxable Traditional part of a withdrawal, given
+// the year's starting balances as tracked through the projection. Proportional withdrawals
+// draw pro-rata from Traditional and Roth; otherwise Traditional money is drawn first, so
+// money already converted to Roth is never taxed a second time.
+func (c *Calculator) calculateTaxableTSPWithdrawal(withdrawal, balance, traditional float64) float64 {
+	if c.config.TSP.WithdrawalSource == "proportional" {
+		if balance <= 0 {
+			return 0
+		}
+		return withdrawal * traditional / balance
+	}
+	return math.Min(withdrawal, traditional)
+}
+
+// defaultBracketFillRate is the federal bracket the bracket_fill source fills with Traditional money
+const defaultBracketFillRate = 0.12
+
+// calculateBracketFillTaxable returns the Traditional part of the year's withdrawal under
+// bracket_fill: as much as fits below the top of the fill bracket given the other income,
+// with the rest drawn from Roth. The RMD on the Traditional balance, and anything the Roth
+// balance cannot cover, still come from Traditional.
+func (c *Calculator) calculateBracketFillTaxable(projection models.AnnualProjection, age int, traditional, roth float64) float64 {
+	projection.GrossIncome = calculateGrossIncome(projection)
+	rate := c.config.TSP.BracketFillRate
+	if rate == 0 {
+		rate = defaultBracketFillRate
+	}
+	ceiling := federalBracketTop(rate, c.filingSchedule(projection)) * c.taxIndexFactor(age)
+	fits := func(taxable float64) bool {
+		projection.TaxableTSPWithdrawal = taxable
+		return c.calculateFederalTaxableIncome(projection, age) <= ceiling
+	}
+	
+	// Taxable income rises with the Traditional draw, so bisect for the largest that fits
+	maxTaxable := math.Min(projection.TSPWithdrawal, traditional)
+	low, high := 0.0, maxTaxable
+	if fits(high) {
+		low = high
+	}
+	for i := 0; i < 50 && high-low > 0.01; i++ {
+		mid := (low + high) / 2
+		if fits(mid) {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	
+	taxable := math.Max(low, math.Max(c.rmdFloor(traditional, age), projection.TSPWithdrawal-roth))
+	return math.Min(taxable, maxTaxable)
+}
+
+// traditionalTSPBalance returns the starting Traditional balance for tax purposes
+// The tax_treatment shortcut treats the whole TSP as one type in place of the entered split.
+func (c *Calculator) traditionalTSPBalance() float64 {
+	switch c.config.TSP.TaxTreatment {
+	case "all_traditional":
+		return c.config.TSP.TraditionalBalance + c.config.TSP.RothBalance
+	case "all_roth":
+		return 0
+	default:
+		return c.config.TSP.TraditionalBalance
+	}
+}
+
+// calculateStrategyWithdrawal calculates the base withdrawal for the configured strategy
+func (c *Calculator) calculateStrategyWithdrawal(balance float64, age int) float64 {
 	switch c.config.TSP.WithdrawalStrategy {
 	case "fixed_amount":
 		if c.config.TSP.WithdrawalAmount > 0 {
-			return math.Min(c.config.TSP.WithdrawalAmount, balance)
+			return math.Min(c.fixedWithdrawalAmount(age), balance)
 		}
 		return 0
 		
@@ -154,6 +662,19 @@ func (c *Calculator) calculateTSPWithdrawal(balance float64, age int) float64 {
 		}
 		return balance * 0.04 // Default 4% rule
 		
+	case "bequest":
+		return c.calculateBequestWithdrawal(balance, age)
+		
+	case "income_only":
+		// Spend the prior year's realized return, leaving principal intact in nominal terms;
+		// the first year has no realized return yet and uses the growth rate
+		prior := c.tspReturnRate(age - c.calculateAgeAtRetirement() - 1)
+		return math.Max(balance*prior, 0)
+		
+	case "custom_schedule":
+		// Planned amounts by age; unlisted ages take nothing beyond the RMD
+		return math.Min(c.config.TSP.WithdrawalSchedule[age], balance)
+		
 	case "lump_sum":
 		// Take everything at retirement
 		if age == c.calculateAgeAtRetirement() {
@@ -166,6 +687,42 @@ func (c *Calculator) calculateTSPWithdrawal(balance float64, age int) float64 {
 	}
 }
 
+// rmdStartAge returns the age RMDs begin under SECURE 2.0 based on birth year
+func (c *Calculator) rmdStartAge() int {
+	birthYear := c.config.Personal.BirthDate.Year()
+	switch {
+	case birthYear < 1951:
+		return 72
+	case birthYear < 1960:
+		return 73
+	default:
+		return 75
+	}
+}
+
+// uniformLifetimeDivisors is the IRS Uniform Lifetime Table (2022) distribution period by age
+var uniformLifetimeDivisors = map[int]float64{
+	72: 27.4, 73: 26.5, 74: 25.5, 75: 24.6, 76: 23.7, 77: 22.9, 78: 22.0, 79: 21.1,
+	80: 20.2, 81: 19.4, 82: 18.5, 83: 17.7, 84: 16.8, 85: 16.0, 86: 15.2, 87: 14.4,
+	88: 13.7, 89: 12.9, 90: 12.2, 91: 11.5, 92: 10.8, 93: 10.1, 94: 9.5, 95: 8.9,
+	96: 8.4, 97: 7.8, 98: 7.3, 99: 6.8, 100: 6.4,
+}
+
+// traditionalRMD calculates the required minimum distribution on the year's starting
+// Traditional balance. Since 2024 (SECURE 2.0) Roth balances in employer plans are exempt,
+// so callers pass the Traditional balance tracked through the projection.
+func (c *Calculator) traditionalRMD(balance float64, age int) float64 {
+	if age < c.rmdStartAge() || balance <= 0 {
+		return 0
+	}
+	
+	divisor, ok := uniformLifetimeDivisors[age]
+	if !ok {
+		divisor = uniformLifetimeDivisors[100]
+	}
+	return balance / divisor
+}
+
 // calculateLifeExpectancy calculates remaining life expectancy for TSP calculations
 func (c *Calculator) calculateLifeExpectancy(age int) float64 {
 	// Simplified IRS Uniform Lifetime Table
@@ -189,29 +746,111 @@ func (c *Calculator) calculateLifeExpectancy(age int) float64 {
 
 // calculateFederalTax calculates federal income tax
 func (c *Calculator) calculateFederalTax(projection models.AnnualProjection, age int) float64 {
+	taxableIncome := c.calculateFederalTaxableIncome(projection, age)
+	if taxableIncome <= 0 {
+		return 0
+	}
+	
+	// Apply tax brackets (simplified); scaling every bracket by the index factor is the
+	// same as taxing the deflated income and inflating the result
+	factor := c.taxIndexFactor(age)
+	return factor * calculateTaxBrackets(taxableIncome/factor, c.filingSchedule(projection))
+}
+
+// taxBaseYear is the tax year of the built-in federal brackets and standard deduction
+const taxBaseYear = 2025
+
+// taxIndexFactor returns how far the federal brackets and standard deduction have grown
+// with inflation by the year at an age, when index_brackets is set
+func (c *Calculator) taxIndexFactor(age int) float64 {
+	year := c.config.Personal.BirthDate.Year() + age
+	if !c.config.TaxInfo.IndexBrackets || year <= taxBaseYear {
+		return 1
+	}
+	return math.Pow(1+c.inflationRate(), float64(year-taxBaseYear))
+}
+
+// calculateAGI calculates adjusted gross income, including the taxable portion of Social Security
+// This is the single place Social Security taxability enters federal income, so the federal
+// tax and MAGI-based items such as IRMAA always see the same taxable amount.
+func (c *Calculator) calculateAGI(projection models.AnnualProjection) float64 {
 	// Simplified federal tax calculation
-	taxableIncome := projection.PensionIncome + projection.TSPWithdrawal
+	agi := projection.PensionIncome + projection.TaxableOtherPension + projection.TaxableTSPWithdrawal +
+		projection.SalaryIncome + projection.AnnualLeavePayout - projection.TSPContribution +
+		rothConversions(projection) + projection.TaxableCashRefill
 	
 	// Add taxable portion of Social Security
-	taxableIncome += c.calculateTaxableSS(projection.SocialSecurityIncome, projection.GrossIncome)
+	return agi + calculateTaxableSS(socialSecurityBenefits(projection), projection.GrossIncome+taxableTransfers(projection), c.filingSchedule(projection))
+}
+
+// socialSecurityBenefits returns the household's Social Security benefits for the year
+// The spousal benefit is taxed like the retiree's own, under the same provisional income test.
+func socialSecurityBenefits(projection models.AnnualProjection) float64 {
+	return projection.SocialSecurityIncome + projection.SpousalSSIncome
+}
+
+// calculateMAGI calculates modified AGI for IRMAA and the net investment income tax
+// Tax-exempt interest is not modeled, so MAGI equals AGI.
+func (c *Calculator) calculateMAGI(projection models.AnnualProjection) float64 {
+	return c.calculateAGI(projection)
+}
+
+// calculateIRMAATier returns the Medicare IRMAA tier for a MAGI, 0 meaning no surcharge
+// Only retirees 65 and older are on Medicare.
+func calculateIRMAATier(magi float64, age int, schedule filingSchedule) int {
+	if age < 65 {
+		return 0
+	}
+	tier := 0
+	for _, threshold := range schedule.irmaaThresholds {
+		if magi > threshold {
+			tier++
+		}
+	}
+	return tier
+}
+
+// calculateFederalTaxableIncome calculates federal taxable income after the standard deduction
+func (c *Calculator) calculateFederalTaxableIncome(projection models.AnnualProjection, age int) float64 {
+	taxableIncome := c.calculateAGI(projection)
 	
-	// Apply standard deduction
-	standardDeduction := 14700.0 // 2025 single standard deduction
+	// Apply standard deduction, with the additional amount for each filer 65 or older
+	schedule := c.filingSchedule(projection)
+	standardDeduction := schedule.standardDeduction
 	if age >= 65 {
-		standardDeduction += 1850.0 // Additional standard deduction for seniors
+		standardDeduction += schedule.seniorDeduction
+	}
+	if schedule.joint && c.spouseAge(age) >= 65 {
+		standardDeduction += schedule.seniorDeduction
 	}
 	
-	taxableIncome -= standardDeduction
-	if taxableIncome <= 0 {
-		return 0
+	return taxableIncome - standardDeduction*c.taxIndexFactor(age)
+}
+
+// marginalRateProbe is the incremental ordinary income used to measure the marginal tax rate
+const marginalRateProbe = 100.0
+
+// calculateTaxRates calculates the effective and marginal federal tax rates for a year
+// The marginal rate probes a small additional taxable TSP withdrawal, so it includes any knock-on
+// increase in the taxable portion of Social Security.
+func (c *Calculator) calculateTaxRates(projection models.AnnualProjection, age int) (float64, float64) {
+	if projection.GrossIncome <= 0 {
+		return 0, 0
 	}
 	
-	// Apply tax brackets (simplified)
-	return c.calculateTaxBrackets(taxableIncome)
+	effective := projection.FederalTax / projection.GrossIncome
+	
+	probe := projection
+	probe.TSPWithdrawal += marginalRateProbe
+	probe.TaxableTSPWithdrawal += marginalRateProbe
+	probe.GrossIncome += marginalRateProbe
+	marginal := (c.calculateFederalTax(probe, age) - projection.FederalTax) / marginalRateProbe
+	
+	return effective, marginal
 }
 
 // calculateTaxableSS calculates taxable portion of Social Security
-func (c *Calculator) calculateTaxableSS(ssBenefit, grossIncome float64) float64 {
+func calculateTaxableSS(ssBenefit, grossIncome float64, schedule filingSchedule) float64 {
 	if ssBenefit == 0 {
 		return 0
 	}
@@ -219,37 +858,89 @@ func (c *Calculator) calculateTaxableSS(ssBenefit, grossIncome float64) float64
 	// Simplified provisional income calculation
 	provisionalIncome := grossIncome - ssBenefit + (ssBenefit * 0.5)
 	
-	// Apply thresholds (single filer)
-	if provisionalIncome <= 25000 {
+	// Apply the filing status thresholds; unlike the brackets these were never indexed to
+	// inflation, so a growing share of benefits becomes taxable over time
+	base, adjusted := schedule.ssBaseAmount, schedule.ssAdjustedBase
+	if provisionalIncome <= base {
 		return 0
 	}
-	if provisionalIncome <= 34000 {
-		return math.Min(ssBenefit*0.5, (provisionalIncome-25000)*0.5)
+	if provisionalIncome <= adjusted {
+		return math.Min(ssBenefit*0.5, (provisionalIncome-base)*0.5)
 	}
 	
 	// Up to 85% taxable
-	return math.Min(ssBenefit*0.85, (provisionalIncome-34000)*0.85+4500)
+	return math.Min(ssBenefit*0.85, (provisionalIncome-adjusted)*0.85+(adjusted-base)*0.5)
 }
 
-// calculateTaxBrackets applies federal tax brackets
-func (c *Calculator) calculateTaxBrackets(income float64) float64 {
-	// 2025 tax brackets (single filer)
-	brackets := []struct {
-		min  float64
-		max  float64
-		rate float64
-	}{
+// federalBracket is one federal income tax bracket
+type federalBracket struct {
+	min  float64
+	max  float64
+	rate float64
+}
+
+// filingSchedule holds the 2025 federal tax values that depend on filing status
+type filingSchedule struct {
+	joint             bool
+	brackets          []federalBracket
+	standardDeduction float64
+	seniorDeduction   float64   // Additional standard deduction for each filer 65 or older
+	ssBaseAmount      float64   // Provisional income above which up to 50% of benefits is taxable
+	ssAdjustedBase    float64   // Provisional income above which up to 85% is taxable
+	irmaaThresholds   []float64 // MAGI thresholds for each Medicare IRMAA tier
+}
+
+// singleSchedule is the single filer schedule, also used for the other filing statuses
+var singleSchedule = filingSchedule{
+	brackets: []federalBracket{
 		{0, 11000, 0.10},
 		{11000, 44725, 0.12},
 		{44725, 95375, 0.22},
-		{95375, 182050, 0.24},
-		{182050, 231250, 0.32},
+		{95375, 182100, 0.24},
+		{182100, 231250, 0.32},
 		{231250, 578125, 0.35},
 		{578125, math.Inf(1), 0.37},
+	},
+	standardDeduction: 14700,
+	seniorDeduction:   1850,
+	ssBaseAmount:      25000,
+	ssAdjustedBase:    34000,
+	irmaaThresholds:   []float64{106000, 133000, 167000, 200000, 500000},
+}
+
+// jointSchedule is the married filing jointly schedule
+var jointSchedule = filingSchedule{
+	joint: true,
+	brackets: []federalBracket{
+		{0, 22000, 0.10},
+		{22000, 89450, 0.12},
+		{89450, 190750, 0.22},
+		{190750, 364200, 0.24},
+		{364200, 462500, 0.32},
+		{462500, 693750, 0.35},
+		{693750, math.Inf(1), 0.37},
+	},
+	standardDeduction: 29400,
+	seniorDeduction:   1500,
+	ssBaseAmount:      32000,
+	ssAdjustedBase:    44000,
+	irmaaThresholds:   []float64{212000, 266000, 334000, 400000, 750000},
+}
+
+// filingSchedule returns the federal tax schedule for a projection year
+// A married couple filing jointly uses the joint schedule while both are alive; after the
+// retiree's death the survivor files single.
+func (c *Calculator) filingSchedule(projection models.AnnualProjection) filingSchedule {
+	if c.config.TaxInfo.FilingStatus == "mfj" && !projection.RetireeDeceased {
+		return jointSchedule
 	}
-	
+	return singleSchedule
+}
+
+// calculateTaxBrackets applies federal tax brackets
+func calculateTaxBrackets(income float64, schedule filingSchedule) float64 {
 	var tax float64
-	for _, bracket := range brackets {
+	for _, bracket := range schedule.brackets {
 		if income <= bracket.min {
 			break
 		}
@@ -261,47 +952,46 @@ func (c *Calculator) calculateTaxBrackets(income float64) float64 {
 	return tax
 }
 
-// calculateStateTax calculates state income tax
-func (c *Calculator) calculateStateTax(projection models.AnnualProjection, age int) float64 {
-	// Use configured state tax rate if available
-	if c.config.TaxInfo.StateTaxRate > 0 {
-		taxableIncome := projection.GrossIncome
-		
-		// Apply exemptions for pension if configured
-		if c.config.TaxInfo.PensionTaxExempt {
-			taxableIncome -= projection.PensionIncome
-		}
-		
-		// Apply exemptions for Social Security if configured
-		if c.config.TaxInfo.SSTaxExempt {
-			taxableIncome -= projection.SocialSecurityIncome
+// federalBracketTop returns the taxable income at the top of the federal bracket with a rate
+func federalBracketTop(rate float64, schedule filingSchedule) float64 {
+	for _, bracket := range schedule.brackets {
+		if bracket.rate == rate {
+			return bracket.max
 		}
-		
-		if taxableIncome <= 0 {
-			return 0
+	}
+	return 0
+}
+
+// calculateTopBracketRate returns the highest federal bracket rate that taxable income reaches
+func calculateTopBracketRate(income float64, schedule filingSchedule) float64 {
+	var rate float64
+	for _, bracket := range schedule.brackets {
+		if income <= bracket.min {
+			break
 		}
-		
-		return taxableIncome * c.config.TaxInfo.StateTaxRate
-	}
-	
-	// Default state tax estimate based on known state patterns
-	stateName := c.config.TaxInfo.State
-	switch stateName {
-	case "FL", "TX", "NV", "AK", "SD", "WY", "WA", "TN", "NH":
-		return 0 // No state income tax
-	case "PA":
-		// PA taxes TSP but not pension
-		return projection.TSPWithdrawal * 0.0307
-	case "IL":
-		// IL has flat 4.95% tax but exempts retirement income over 65
-		if age >= 65 {
-			return projection.TSPWithdrawal * 0.0495
+		rate = bracket.rate
+	}
+	return rate
+}
+
+// calculateRequiredSpending calculates inflation-indexed required spending for a year
+func (c *Calculator) calculateRequiredSpending(age, startAge int) float64 {
+	spending := c.config.Spending.AnnualAmount
+	if spending <= 0 {
+		return 0
+	}
+	return spending * math.Pow(1+c.inflationRate(), float64(age-startAge))
+}
+
+// calculateOneTimeExpenses totals the one-time expenses falling at an age
+func (c *Calculator) calculateOneTimeExpenses(age int) float64 {
+	var total float64
+	for _, expense := range c.config.Spending.OneTimeExpenses {
+		if expense.Age == age {
+			total += expense.Amount
 		}
-		return projection.GrossIncome * 0.0495
-	default:
-		// Default 5% state tax rate for unknown states
-		return projection.GrossIncome * 0.05
 	}
+	return total
 }
 
 // calculateHealthInsurance calculates health insurance premiums
@@ -309,9 +999,24 @@ func (c *Calculator) calculateHealthInsurance(age int) float64 {
 	startAge := c.calculateAgeAtRetirement()
 	yearsRetired := age - startAge
 	
+	// While still employed during phased retirement the active enrollee share applies. After
+	// the retiree's death, a spouse can continue FEHB only while receiving a survivor
+	// annuity, and pays their own premium from it
+	basePremium := c.retirementPremium()
+	if current := c.config.HealthInsurance.CurrentPremium; current > 0 && c.isFederallyEmployed(age) {
+		basePremium = current
+	}
+	if c.isDeceased(age) {
+		if c.config.Retirement.SurvivorBenefit == "none" {
+			return 0
+		}
+		if premium := c.config.HealthInsurance.SurvivorPremium; premium > 0 {
+			basePremium = premium
+		}
+	}
+	
 	// Use configured premiums if available
-	if c.config.HealthInsurance.RetirementPremium > 0 {
-		basePremium := c.config.HealthInsurance.RetirementPremium
+	if basePremium > 0 {
 		
 		// Apply COLA if specified
 		if c.config.HealthInsurance.PremiumCOLA > 0 && yearsRetired > 0 {
@@ -323,7 +1028,7 @@ func (c *Calculator) calculateHealthInsurance(age int) float64 {
 	}
 	
 	// Default FEHB premium estimate
-	basePremium := 4800.0 // $400/month
+	basePremium = 4800.0 // $400/month
 	
 	// Apply default 3% annual increase
 	if yearsRetired > 0 {
@@ -333,16 +1038,55 @@ func (c *Calculator) calculateHealthInsurance(age int) float64 {
 	return basePremium
 }
 
+// hsaMedicareAge is the age from which HSA money may pay health insurance premiums tax-free
+const hsaMedicareAge = 65
+
+// calculateHSAWithdrawal returns the HSA money spent on the year's health insurance premium
+func (c *Calculator) calculateHSAWithdrawal(premium, balance float64, age int) float64 {
+	if age < hsaMedicareAge || balance <= 0 {
+		return 0
+	}
+	withdrawal := math.Min(premium, balance)
+	if limit := c.config.HSA.MaxAnnualWithdrawal; limit > 0 {
+		withdrawal = math.Min(withdrawal, limit)
+	}
+	return withdrawal
+}
+
+// retirementPremium returns the annual FEHB enrollee share in retirement
+// Retirees pay the same enrollee share as active employees, so the active premium
+// carries into retirement unless a different retirement premium is configured.
+func (c *Calculator) retirementPremium() float64 {
+	if c.config.HealthInsurance.RetirementPremium > 0 {
+		return c.config.HealthInsurance.RetirementPremium
+	}
+	return c.config.HealthInsurance.CurrentPremium
+}
+
 // calculateLifeInsurance calculates life insurance premiums
-func (c *Calculator) calculateLifeInsurance(_ int) float64 {
+// FEGLI coverage ends with the retiree's death, so survivors pay no premium.
+func (c *Calculator) calculateLifeInsurance(age int) float64 {
+	if c.isDeceased(age) {
+		return 0
+	}
+	
 	// Simplified FEGLI premium estimate
 	return 600.0 // $50/month
 }
 
 // calculateCOLA calculates Cost of Living Adjustment
 func (c *Calculator) calculateCOLA(_, _ int) float64 {
-	// Simplified COLA calculation
-	return 0.025 // 2.5% average
+	// Simplified COLA calculation using the assumed average rate
+	return c.colaRate()
+}
+
+// annuityCOLARate returns the rate annuity COLAs are paid at: the diet COLA under FERS and
+// the full assumed rate under CSRS. The diet rule must never reach a CSRS annuity.
+func (c *Calculator) annuityCOLARate() float64 {
+	if c.config.Personal.RetirementSystem == "FERS" {
+		return c.calculateFERSCOLA(c.colaRate())
+	}
+	return c.colaRate()
 }
 
 // calculateFERSCOLA applies FERS COLA rules
@@ -0,0 +1,60 @@
+package calc
+
+import (
+	"runtime"
+	"sync"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// calculateScenarios runs Calculate for each config on a worker pool bounded by GOMAXPROCS
+// Results are returned in the same order as configs regardless of completion order.
+func calculateScenarios(configs []*models.Config) ([]models.RetirementResults, error) {
+	return calculateScenariosWithWorkers(configs, runtime.GOMAXPROCS(0))
+}
+
+// calculateScenariosWithWorkers runs Calculate for each config using up to workers goroutines
+// The Calculator only reads its config, so scenarios can safely run concurrently.
+func calculateScenariosWithWorkers(configs []*models.Config, workers int) ([]models.RetirementResults, error) {
+	results := make([]models.RetirementResults, len(configs))
+	errs := make([]error, len(configs))
+
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(configs) {
+		workers = len(configs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, err := NewCalculator(configs[i]).Calculate()
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i] = *result
+			}
+		}()
+	}
+
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Report the first failing scenario in input order
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
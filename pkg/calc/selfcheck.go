@@ -0,0 +1,31 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// accountingTolerance absorbs floating-point rounding in the accounting identities
+const accountingTolerance = 1e-6
+
+// CheckAccounting verifies the accounting identities of every projection year:
+// net income is gross income less total deductions, and total deductions is the
+// sum of federal tax, state tax, health insurance net of HSA withdrawals, life insurance,
+// and TSP contributions.
+func CheckAccounting(projections []models.AnnualProjection) error {
+	for _, p := range projections {
+		deductions := p.FederalTax + p.StateTax + p.HealthInsurance - p.HSAWithdrawal + p.LifeInsurance + p.TSPContribution
+		if math.Abs(p.TotalDeductions-deductions) > accountingTolerance {
+			return fmt.Errorf("accounting check failed at age %d: total deductions %.2f != sum of components %.2f",
+				p.Age, p.TotalDeductions, deductions)
+		}
+
+		if net := p.GrossIncome - p.TotalDeductions; math.Abs(p.NetIncome-net) > accountingTolerance {
+			return fmt.Errorf("accounting check failed at age %d: net income %.2f != gross income less deductions %.2f",
+				p.Age, p.NetIncome, net)
+		}
+	}
+	return nil
+}
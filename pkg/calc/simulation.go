@@ -0,0 +1,96 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// Simulated returns are clamped to the range a return sequence accepts
+const (
+	minSimulatedReturn = -0.9
+	maxSimulatedReturn = 1.0
+)
+
+// Simulate runs the plan once per trial with random annual TSP returns, drawn from a normal
+// distribution around the growth rate with the given volatility, and summarizes the outcomes.
+// The same seed always produces the same trials.
+func Simulate(base *models.Config, trials int, volatility float64, seed int64) (models.SimulationSummary, error) {
+	if trials <= 0 {
+		return models.SimulationSummary{}, fmt.Errorf("trials must be positive")
+	}
+	if volatility < 0 {
+		return models.SimulationSummary{}, fmt.Errorf("volatility cannot be negative")
+	}
+
+	calculator := NewCalculator(base)
+	years := projectionEndAge - calculator.calculateAgeAtRetirement() + 1
+	if years < 1 {
+		years = 1
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	configs := make([]*models.Config, 0, trials)
+	for i := 0; i < trials; i++ {
+		config := *base
+		config.TSP.ReturnSequence = make([]float64, years)
+		for y := range config.TSP.ReturnSequence {
+			r := calculator.growthRate() + rng.NormFloat64()*volatility
+			config.TSP.ReturnSequence[y] = math.Max(minSimulatedReturn, math.Min(r, maxSimulatedReturn))
+		}
+		configs = append(configs, &config)
+	}
+
+	results, err := calculateScenarios(configs)
+	if err != nil {
+		return models.SimulationSummary{}, err
+	}
+
+	endingBalances := make([]float64, 0, len(results))
+	survived := 0
+	for _, result := range results {
+		if n := len(result.AnnualProjections); n > 0 {
+			endingBalances = append(endingBalances, result.AnnualProjections[n-1].TSPEndBalance)
+		}
+		if result.Summary.TSPProjectedDepletion == 0 {
+			survived++
+		}
+	}
+	return summarizeSimulation(endingBalances, survived), nil
+}
+
+// summarizeSimulation reports ending balance percentiles and the share of trials the TSP survived
+func summarizeSimulation(endingBalances []float64, survived int) models.SimulationSummary {
+	sorted := append([]float64(nil), endingBalances...)
+	sort.Float64s(sorted)
+
+	summary := models.SimulationSummary{
+		Trials:              len(sorted),
+		SurvivedTrials:      survived,
+		EndingBalanceP10:    interpolatedPercentile(sorted, 0.10),
+		EndingBalanceP25:    interpolatedPercentile(sorted, 0.25),
+		EndingBalanceMedian: interpolatedPercentile(sorted, 0.50),
+		EndingBalanceP75:    interpolatedPercentile(sorted, 0.75),
+		EndingBalanceP90:    interpolatedPercentile(sorted, 0.90),
+	}
+	if summary.Trials > 0 {
+		summary.SuccessRate = float64(survived) / float64(summary.Trials)
+	}
+	return summary
+}
+
+// interpolatedPercentile returns the p-th quantile (0 to 1) of sorted values, interpolating
+// linearly between the two nearest ranks so the result moves smoothly as trials are added
+func interpolatedPercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
@@ -0,0 +1,164 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// netIncomeTolerance is how close the solved withdrawal must come to the target net income
+const netIncomeTolerance = 0.01
+
+// SolvePensionTarget back-solves the high-3 and, separately, the additional years of
+// service needed for the unreduced annual annuity to reach target
+func (c *Calculator) SolvePensionTarget(target float64) (models.PensionTargetSolution, error) {
+	if target <= 0 {
+		return models.PensionTargetSolution{}, fmt.Errorf("target pension must be greater than zero")
+	}
+
+	service := c.annuityServiceYears()
+	high3 := c.high3Salary()
+	age := c.calculateAgeAtRetirement()
+	if service <= 0 || high3 <= 0 {
+		return models.PensionTargetSolution{}, fmt.Errorf("creditable service and high-3 must be greater than zero")
+	}
+
+	solution := models.PensionTargetSolution{
+		RetirementSystem: c.config.Personal.RetirementSystem,
+		TargetPension:    target,
+		CurrentHigh3:     high3,
+		CurrentService:   service,
+	}
+
+	if c.config.Personal.RetirementSystem == "FERS" {
+		solution.CurrentPension = c.calculateFERSPension(service, high3, age)
+		solution.Multiplier = solution.CurrentPension / (high3 * service)
+		solution.AdditionalYears = target/(high3*solution.Multiplier) - service
+	} else {
+		solution.CurrentPension = c.calculateCSRSPension(service, high3, age)
+		solution.AdditionalYears = c.solveCSRSService(target, high3, age) - service
+	}
+
+	// Both formulas are linear in the high-3, so the required high-3 scales directly
+	solution.RequiredHigh3 = high3 * target / solution.CurrentPension
+	solution.RequiredRaisePct = (solution.RequiredHigh3/high3 - 1) * 100
+
+	if solution.AdditionalYears < 0 {
+		solution.AdditionalYears = 0
+	}
+
+	return solution, nil
+}
+
+// SolveTargetNetIncome finds the fixed annual TSP withdrawal whose recurring first-year net
+// income after taxes, without the one-time annual leave payout, meets target, and returns
+// the projection using that withdrawal. Withdrawal
+// ceilings, floors, and RMDs still apply, so a target may be unreachable.
+func (c *Calculator) SolveTargetNetIncome(target float64) (*models.RetirementResults, error) {
+	if target <= 0 {
+		return nil, fmt.Errorf("target net income must be greater than zero")
+	}
+
+	calculate := func(withdrawal float64) (*models.RetirementResults, error) {
+		cfg := *c.config
+		cfg.TSP.WithdrawalStrategy = "fixed_amount"
+		cfg.TSP.WithdrawalAmount = withdrawal
+		cfg.TSP.WithdrawalRate = 0
+		cfg.TSP.WithdrawalBasis = "gross"
+		return NewCalculator(&cfg).Calculate()
+	}
+	firstYearNet := func(results *models.RetirementResults) float64 {
+		if len(results.AnnualProjections) == 0 {
+			return 0
+		}
+		return c.recurringFirstYear(results.AnnualProjections[0]).NetIncome
+	}
+
+	// Net income rises with the withdrawal because the marginal tax rate is below 100%,
+	// so bisect between no withdrawal and the whole balance
+	low, high := 0.0, c.config.TSP.TraditionalBalance+c.config.TSP.RothBalance
+	results, err := calculate(high)
+	if err != nil {
+		return nil, err
+	}
+	if net := firstYearNet(results); net < target-netIncomeTolerance {
+		return nil, fmt.Errorf("target net income of $%.2f is not reachable; the most the TSP can provide is $%.2f", target, net)
+	}
+
+	for i := 0; i < 100 && high-low > netIncomeTolerance; i++ {
+		mid := (low + high) / 2
+		if results, err = calculate(mid); err != nil {
+			return nil, err
+		}
+		if firstYearNet(results) < target {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	if results, err = calculate(high); err != nil {
+		return nil, err
+	}
+	results.Summary.TargetNetIncome = target
+	if len(results.AnnualProjections) > 0 {
+		results.Summary.SolvedTSPWithdrawal = results.AnnualProjections[0].TSPWithdrawal
+	}
+	return results, nil
+}
+
+// grossUpWithdrawal finds the TSP withdrawal that leaves the fixed withdrawal amount after
+// the federal and state tax the withdrawal adds to the year's other income, then applies
+// the ceiling, floor, and RMD to that gross amount
+func (c *Calculator) grossUpWithdrawal(projection models.AnnualProjection, age int, balance, traditionalBalance float64) float64 {
+	if balance <= 0 {
+		return 0
+	}
+
+	taxWith := func(withdrawal float64) float64 {
+		trial := projection
+		trial.TSPWithdrawal = withdrawal
+		if c.config.TSP.WithdrawalSource == "bracket_fill" {
+			trial.TaxableTSPWithdrawal = c.calculateBracketFillTaxable(trial, age, traditionalBalance, balance-traditionalBalance)
+		} else {
+			trial.TaxableTSPWithdrawal = c.calculateTaxableTSPWithdrawal(withdrawal, balance, traditionalBalance)
+		}
+		trial.GrossIncome = calculateGrossIncome(trial)
+		return c.calculateFederalTax(trial, age) + c.calculateStateTax(trial, age)
+	}
+	baseTax := taxWith(0)
+	netOf := func(withdrawal float64) float64 {
+		return withdrawal - (taxWith(withdrawal) - baseTax)
+	}
+
+	// The net left rises with the withdrawal because the marginal tax rate is below 100%,
+	// so bisect between the net amount itself and the whole balance
+	target := math.Min(c.fixedWithdrawalAmount(age), balance)
+	low, high := target, balance
+	if netOf(high) >= target {
+		for i := 0; i < 100 && high-low > netIncomeTolerance; i++ {
+			mid := (low + high) / 2
+			if netOf(mid) < target {
+				low = mid
+			} else {
+				high = mid
+			}
+		}
+	}
+	return c.limitWithdrawal(high, balance, traditionalBalance, age)
+}
+
+// solveCSRSService finds the total service at which the tiered CSRS formula reaches target
+func (c *Calculator) solveCSRSService(target, high3 float64, age int) float64 {
+	low, high := 0.0, 80.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if c.calculateCSRSPension(mid, high3, age) < target {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return high
+}
@@ -0,0 +1,152 @@
+package calc
+
+import (
+	"math"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// stateResidence describes the state tax rules in effect for a projection year
+type stateResidence struct {
+	State            string
+	StateTaxRate     float64
+	PensionTaxExempt bool
+	SSTaxExempt      bool
+	CustomStateTax   *models.CustomStateTax
+	MoveAge          int // Age the move into this state took effect, or -1 for the configured state
+	MoveMonth        int // Month of the move within the year at MoveAge, if configured
+}
+
+// residenceForAge returns the state of residence in effect at an age
+// The configured tax_info state applies until the latest residence change at or before age.
+func (c *Calculator) residenceForAge(age int) stateResidence {
+	residence := stateResidence{
+		State:            c.config.TaxInfo.State,
+		StateTaxRate:     c.config.TaxInfo.StateTaxRate,
+		PensionTaxExempt: c.config.TaxInfo.PensionTaxExempt,
+		SSTaxExempt:      c.config.TaxInfo.SSTaxExempt,
+		CustomStateTax:   c.config.TaxInfo.CustomStateTax,
+		MoveAge:          -1,
+	}
+
+	effectiveAge := -1
+	for _, change := range c.config.TaxInfo.ResidenceChanges {
+		if change.Age <= age && change.Age > effectiveAge {
+			effectiveAge = change.Age
+			residence = stateResidence{
+				State:            change.State,
+				StateTaxRate:     change.StateTaxRate,
+				PensionTaxExempt: change.PensionTaxExempt,
+				SSTaxExempt:      change.SSTaxExempt,
+				CustomStateTax:   change.CustomStateTax,
+				MoveAge:          change.Age,
+				MoveMonth:        change.Month,
+			}
+		}
+	}
+
+	return residence
+}
+
+// calculateStateTax calculates state income tax for the state of residence that year
+// In the year of a mid-year move, each state taxes the share of the year's income
+// matching the months lived there.
+func (c *Calculator) calculateStateTax(projection models.AnnualProjection, age int) float64 {
+	residence := c.residenceForAge(age)
+	tax := c.calculateResidenceStateTax(residence, projection, age)
+	if residence.MoveAge != age || residence.MoveMonth <= 1 {
+		return tax
+	}
+
+	priorShare := float64(residence.MoveMonth-1) / 12
+	prior := c.calculateResidenceStateTax(c.residenceForAge(age-1), projection, age)
+	return prior*priorShare + tax*(1-priorShare)
+}
+
+// calculateResidenceStateTax calculates state income tax under one state's rules
+func (c *Calculator) calculateResidenceStateTax(residence stateResidence, projection models.AnnualProjection, age int) float64 {
+	if residence.CustomStateTax != nil {
+		return calculateCustomStateTax(residence.CustomStateTax, projection, age)
+	}
+	
+	// Use configured state tax rate if available
+	if residence.StateTaxRate > 0 {
+		taxableIncome := projection.GrossIncome + taxableTransfers(projection)
+
+		// Apply exemptions for pension if configured
+		if residence.PensionTaxExempt {
+			taxableIncome -= projection.PensionIncome + projection.OtherPensionIncome
+		}
+
+		// Apply exemptions for Social Security if configured
+		if residence.SSTaxExempt {
+			taxableIncome -= socialSecurityBenefits(projection)
+		}
+
+		if taxableIncome <= 0 {
+			return 0
+		}
+
+		return taxableIncome * residence.StateTaxRate
+	}
+
+	// Default state tax estimate based on known state patterns
+	switch residence.State {
+	case "FL", "TX", "NV", "AK", "SD", "WY", "WA", "TN", "NH":
+		return 0 // No state income tax
+	case "PA":
+		// PA taxes TSP but not pension
+		return (projection.TaxableTSPWithdrawal + projection.TaxableCashRefill) * 0.0307
+	case "IL":
+		// IL has flat 4.95% tax but exempts retirement income over 65
+		if age >= 65 {
+			return (projection.TaxableTSPWithdrawal + projection.TaxableCashRefill) * 0.0495
+		}
+		return (projection.GrossIncome + taxableTransfers(projection)) * 0.0495
+	default:
+		// Default 5% state tax rate for unknown states
+		return (projection.GrossIncome + taxableTransfers(projection)) * 0.05
+	}
+}
+
+// rothConversions returns the year's Roth conversions, which are taxable but not gross income
+func rothConversions(projection models.AnnualProjection) float64 {
+	return projection.TSPRothConversion + projection.IRARothConversion
+}
+
+// taxableTransfers adjusts gross income to taxable income for money moved rather than spent:
+// Roth conversions and the Traditional share of cash reserve refills are taxed in the year
+// they are made, while cash reserve spending is gross income that was taxed when it left the TSP
+func taxableTransfers(projection models.AnnualProjection) float64 {
+	return rothConversions(projection) + projection.TaxableCashRefill - projection.CashWithdrawal
+}
+
+// calculateCustomStateTax calculates state income tax under a user-defined bracket table
+func calculateCustomStateTax(rule *models.CustomStateTax, projection models.AnnualProjection, age int) float64 {
+	taxableIncome := projection.GrossIncome + taxableTransfers(projection)
+	if rule.PensionExempt {
+		taxableIncome -= projection.PensionIncome + projection.OtherPensionIncome
+	}
+	if rule.SSExempt {
+		taxableIncome -= socialSecurityBenefits(projection)
+	}
+	if rule.TSPExempt {
+		taxableIncome -= projection.TSPWithdrawal
+	}
+	if age >= 65 {
+		taxableIncome -= rule.Age65Exclusion
+	}
+
+	var tax float64
+	for i, bracket := range rule.Brackets {
+		if taxableIncome <= bracket.Over {
+			break
+		}
+		top := taxableIncome
+		if i+1 < len(rule.Brackets) {
+			top = math.Min(taxableIncome, rule.Brackets[i+1].Over)
+		}
+		tax += (top - bracket.Over) * bracket.Rate
+	}
+	return tax
+}
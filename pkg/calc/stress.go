@@ -0,0 +1,81 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// Adverse conditions applied by the stress test; each only makes the plan's own
+// assumptions worse, never better
+const (
+	stressGrowthRate    = 0.04 // Low TSP returns
+	stressInflationRate = 0.04 // High inflation raises required spending
+	stressSSCutYear     = 2033 // Trust fund depletion with no action from Congress
+	stressSSCut         = 0.23
+	stressDeathAge      = 75 // Early death of a married retiree, leaving the survivor
+)
+
+// StressTest runs the plan as configured and again under a fixed set of adverse
+// conditions, and reports whether each still meets the required spending
+func StressTest(base *models.Config) (models.StressTestResult, error) {
+	if base.Spending.AnnualAmount <= 0 {
+		return models.StressTestResult{}, fmt.Errorf("stress test requires spending.annual_amount to measure the plan against")
+	}
+
+	stressed, conditions := applyStressConditions(base)
+	results, err := calculateScenarios([]*models.Config{base, stressed})
+	if err != nil {
+		return models.StressTestResult{}, err
+	}
+
+	return models.StressTestResult{
+		Conditions: conditions,
+		Base:       newStressCase(results[0].Summary),
+		Stress:     newStressCase(results[1].Summary),
+	}, nil
+}
+
+// applyStressConditions returns a copy of the config under the stress test's adverse
+// conditions, with a description of each condition applied
+func applyStressConditions(base *models.Config) (*models.Config, []string) {
+	config := *base
+	var conditions []string
+
+	calculator := NewCalculator(base)
+	config.TSP.GrowthRate = models.Rate(math.Min(calculator.growthRate(), stressGrowthRate))
+	config.TSP.ReturnSequence = nil
+	conditions = append(conditions, fmt.Sprintf("TSP returns of %.1f%% a year", *config.TSP.GrowthRate*100))
+
+	config.Assumptions.InflationRate = models.Rate(math.Max(calculator.inflationRate(), stressInflationRate))
+	conditions = append(conditions, fmt.Sprintf("Inflation of %.1f%% a year", *config.Assumptions.InflationRate*100))
+
+	ss := &config.SocialSecurity
+	if ss.TrustFundCutYear == 0 || ss.TrustFundCutYear > stressSSCutYear {
+		ss.TrustFundCutYear = stressSSCutYear
+	}
+	ss.TrustFundCut = math.Max(ss.TrustFundCut, stressSSCut)
+	conditions = append(conditions, fmt.Sprintf("Social Security cut %.0f%% from %d", ss.TrustFundCut*100, ss.TrustFundCutYear))
+
+	// Early death only stresses the plan when a survivor is left to live on it
+	if config.Personal.MaritalStatus == "married" {
+		if config.Personal.AssumedDeathAge == 0 || config.Personal.AssumedDeathAge > stressDeathAge {
+			config.Personal.AssumedDeathAge = stressDeathAge
+		}
+		conditions = append(conditions, fmt.Sprintf("Retiree dies at %d, leaving the survivor", config.Personal.AssumedDeathAge))
+	}
+
+	return &config, conditions
+}
+
+// newStressCase summarizes whether a run meets the required spending
+func newStressCase(summary models.RetirementSummary) models.StressCase {
+	return models.StressCase{
+		MeetsSpending:     summary.ShortfallYears == 0,
+		ShortfallYears:    summary.ShortfallYears,
+		FirstShortfallAge: summary.FirstShortfallAge,
+		TotalShortfall:    summary.TotalShortfall,
+		TSPDepletionAge:   summary.TSPProjectedDepletion,
+	}
+}
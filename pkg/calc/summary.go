@@ -1,8 +1,9 @@
 package calc
 
 import (
-	"strconv"
-	"time"
+	"fmt"
+	"math"
+	"sort"
 
 	"rgehrsitz/ferex_cli/internal/models"
 )
@@ -10,6 +11,7 @@ import (
 // createSummary creates a retirement summary from calculations
 func (c *Calculator) createSummary(pension models.PensionCalculation, ss models.SocialSecurityCalculation, fersup models.FERSSupplementCalculation, projections []models.AnnualProjection) models.RetirementSummary {
 	summary := models.RetirementSummary{
+		CreditableService:     c.annuityServiceYears(),
 		MonthlyPension:        pension.FinalPension / 12,
 		AnnualPension:         pension.FinalPension,
 		PensionReductionPct:   pension.ReductionPercent,
@@ -18,6 +20,27 @@ func (c *Calculator) createSummary(pension models.PensionCalculation, ss models.
 		MonthlySocialSecurity: ss.MonthlyBenefit,
 		SocialSecurityStartAge: ss.ClaimingAge,
 		TSPStartingBalance:    c.config.TSP.TraditionalBalance + c.config.TSP.RothBalance,
+		SustainableWithdrawalRate: c.calculateSustainableWithdrawalRate(),
+	}
+
+	// One-time payments at retirement appear only when the projection starts there,
+	// not for a plan that is already retired
+	if len(projections) > 0 {
+		summary.ExcessContributionRefund = projections[0].ContributionRefund
+		summary.AnnualLeavePayout = projections[0].AnnualLeavePayout
+	}
+
+	// Survivor election break-even
+	if pension.SurvivorCost > 0 {
+		summary.SurvivorAnnuity = c.calculateSurvivorAnnuity(pension)
+		summary.SurvivorBreakEvenAge = c.calculateSurvivorBreakEvenAge(pension.SurvivorCost, summary.SurvivorAnnuity)
+	}
+
+	// Phased retirement info
+	if phased := c.config.Retirement.PhasedRetirement; phased != nil {
+		summary.PhasedAnnuity = pension.PhasedPension
+		summary.CompositeAnnuity = pension.CompositePension
+		summary.PhasedEndAge = c.calculateAgeAtRetirement() + phased.Years
 	}
 
 	// FERS Supplement info
@@ -28,13 +51,71 @@ func (c *Calculator) createSummary(pension models.PensionCalculation, ss models.
 
 	// Calculate first year income and lifetime totals
 	if len(projections) > 0 {
-		summary.FirstYearIncome = projections[0].NetIncome
+		firstYear := c.recurringFirstYear(projections[0])
+		
+		summary.FirstYearIncome = firstYear.NetIncome
+		summary.FirstYearIncomeMix = calculateIncomeComposition(projections[:1])
+		summary.LifetimeIncomeMix = calculateIncomeComposition(projections)
 		summary.LifetimeIncome = c.calculateLifetimeIncome(projections)
-		summary.ReplacementRatio = c.calculateReplacementRatio(projections[0])
+		if c.config.Assumptions.MortalityWeighted {
+			summary.ExpectedLifetimeIncome = c.calculateExpectedLifetimeIncome(projections)
+		}
+		if rate := c.config.Assumptions.DiscountRate; rate > 0 {
+			summary.LifetimeIncomeNPV = calculateLifetimeIncomeNPV(projections, rate)
+		}
+		summary.ReplacementRatio = c.calculateReplacementRatio(firstYear)
+		summary.ReplacementRatioBasis = c.replacementRatioBasis()
+		summary.WorkingNetIncome = c.calculateWorkingNetIncome()
+		if summary.WorkingNetIncome > 0 {
+			summary.NetReplacementRatio = firstYear.NetIncome / summary.WorkingNetIncome
+		}
 	}
 
 	// Find TSP depletion age
 	summary.TSPProjectedDepletion = c.findTSPDepletionAge(projections)
+	summary.Timeline = c.buildTimeline(projections)
+
+	// Find the gap before Social Security begins
+	summary.IncomeGapStartAge, summary.IncomeGapYears, summary.IncomeGapDip = c.findIncomeGap(projections)
+	
+	// Suggest claiming at 62 when Social Security does not pick up where the supplement ends
+	summary.SupplementGapYears, summary.SupplementGapLoss = c.findSupplementGap(projections)
+	if summary.SupplementGapYears > 0 {
+		summary.Suggestions = append(summary.Suggestions, fmt.Sprintf(
+			"Claim Social Security at %d instead of %d to avoid %d years without the FERS supplement or Social Security, "+
+				"about $%.0f of income; claiming early permanently reduces the benefit",
+			ssEarliestClaimingAge, ss.ClaimingAge, summary.SupplementGapYears, summary.SupplementGapLoss))
+	}
+
+	// Total Roth conversions by source
+	for _, p := range projections {
+		summary.TSPRothConversions += p.TSPRothConversion
+		summary.IRARothConversions += p.IRARothConversion
+	}
+
+	// Summarize spending shortfalls
+	for _, p := range projections {
+		if p.Shortfall <= 0 {
+			continue
+		}
+		if summary.ShortfallYears == 0 {
+			summary.FirstShortfallAge = p.Age
+		}
+		summary.ShortfallYears++
+		summary.TotalShortfall += p.Shortfall
+	}
+
+	summary.DataQuality = c.dataQualityNotes()
+
+	// Find the first tax cliff
+	for _, p := range projections {
+		if p.BracketCrossing {
+			summary.FirstTaxCliffAge = p.Age
+			summary.FirstTaxCliffYear = p.Year
+			summary.TaxCliffBracketRate = p.TopBracketRate
+			break
+		}
+	}
 
 	return summary
 }
@@ -42,21 +123,43 @@ func (c *Calculator) createSummary(pension models.PensionCalculation, ss models.
 // createMetadata creates calculation metadata
 func (c *Calculator) createMetadata() models.CalculationMetadata {
 	return models.CalculationMetadata{
-		CalculationDate:   time.Now(),
+		CalculationDate:   c.now,
 		ConfigVersion:     "1.0",
 		CalculationEngine: "ferex-cli-v1.0",
+		Inputs: models.CalculationInputs{
+			Name:             c.config.Personal.Name,
+			RetirementSystem: c.config.Personal.RetirementSystem,
+			RetirementAge:    c.calculateAgeAtRetirement(),
+			High3Salary:      c.high3Salary(),
+		},
 		Assumptions: models.CalculationAssumptions{
-			InflationRate:      0.025,
-			TSPGrowthRate:      c.config.TSP.GrowthRate,
-			LifeExpectancy:     95,
-			FERSCOLARate:       0.025,
-			SocialSecurityCOLA: 0.025,
+			Profile:            c.config.Assumptions.Profile,
+			InflationRate:      c.inflationRate(),
+			TSPGrowthRate:      c.growthRate(),
+			LifeExpectancy:     projectionEndAge,
+			FERSCOLARate:       c.colaRate(),
+			SocialSecurityCOLA: c.colaRate(),
 			TaxBracketYear:     2025,
 		},
 		Warnings: c.generateWarnings(),
 	}
 }
 
+// calculateSurvivorBreakEvenAge finds the retiree death age at which the cumulative survivor
+// election cost equals the survivor annuity paid afterward, assuming the survivor lives to the
+// projection end age. Dying earlier than this age makes the election pay off.
+func (c *Calculator) calculateSurvivorBreakEvenAge(annualCost, survivorAnnuity float64) float64 {
+	if annualCost <= 0 || survivorAnnuity <= 0 {
+		return 0
+	}
+	
+	startAge := float64(c.calculateAgeAtRetirement())
+	endAge := float64(projectionEndAge)
+	
+	// annualCost * (D - start) = survivorAnnuity * (end - D)
+	return (annualCost*startAge + survivorAnnuity*endAge) / (annualCost + survivorAnnuity)
+}
+
 // calculateLifetimeIncome sums projected lifetime income
 func (c *Calculator) calculateLifetimeIncome(projections []models.AnnualProjection) float64 {
 	var total float64
@@ -66,12 +169,151 @@ func (c *Calculator) calculateLifetimeIncome(projections []models.AnnualProjecti
 	return total
 }
 
-// calculateReplacementRatio calculates income replacement ratio
+// calculateLifetimeIncomeNPV discounts each year's net income to the first projection year
+func calculateLifetimeIncomeNPV(projections []models.AnnualProjection, rate float64) float64 {
+	if len(projections) == 0 {
+		return 0
+	}
+	
+	startAge := projections[0].Age
+	var total float64
+	for _, p := range projections {
+		total += p.NetIncome / math.Pow(1+rate, float64(p.Age-startAge))
+	}
+	return total
+}
+
+// calculateIncomeComposition returns each source's share of gross income over the projections
+func calculateIncomeComposition(projections []models.AnnualProjection) models.IncomeComposition {
+	var mix models.IncomeComposition
+	for _, p := range projections {
+		mix.Pension += p.PensionIncome
+		mix.SocialSecurity += p.SocialSecurityIncome + p.SpousalSSIncome
+		mix.Supplement += p.FERSSupplementIncome
+		mix.TSP += p.TSPWithdrawal + p.CashWithdrawal
+		mix.Other += p.SalaryIncome + p.OtherPensionIncome
+	}
+	
+	total := mix.Pension + mix.SocialSecurity + mix.Supplement + mix.TSP + mix.Other
+	if total <= 0 {
+		return models.IncomeComposition{}
+	}
+	mix.Pension /= total
+	mix.SocialSecurity /= total
+	mix.Supplement /= total
+	mix.TSP /= total
+	mix.Other /= total
+	return mix
+}
+
+// Gompertz law of mortality parameters approximating US retiree mortality:
+// the modal age at death and the dispersion of deaths around it, in years
+const (
+	mortalityModalAge   = 88.0
+	mortalityDispersion = 10.0
+)
+
+// survivalProbability returns the probability that someone alive at fromAge is alive at toAge
+func survivalProbability(fromAge, toAge float64) float64 {
+	return math.Exp(math.Exp((fromAge-mortalityModalAge)/mortalityDispersion) *
+		(1 - math.Exp((toAge-fromAge)/mortalityDispersion)))
+}
+
+// calculateExpectedLifetimeIncome weights each year's net income by the probability of
+// being alive that year, given survival to retirement
+func (c *Calculator) calculateExpectedLifetimeIncome(projections []models.AnnualProjection) float64 {
+	if len(projections) == 0 {
+		return 0
+	}
+	
+	startAge := float64(projections[0].Age)
+	var total float64
+	for _, p := range projections {
+		total += p.NetIncome * survivalProbability(startAge, float64(p.Age))
+	}
+	return total
+}
+
+// calculateReplacementRatio calculates income replacement ratio on the configured basis
+// The final salary falls back to the high-3 when it is not configured.
 func (c *Calculator) calculateReplacementRatio(firstYear models.AnnualProjection) float64 {
-	preRetirementIncome := c.config.Employment.High3Salary
+	if c.replacementRatioBasis() == "gross_final_salary" {
+		finalSalary := c.config.Employment.FinalSalary
+		if finalSalary == 0 {
+			finalSalary = c.high3Salary()
+		}
+		return firstYear.GrossIncome / finalSalary
+	}
+	
+	preRetirementIncome := c.high3Salary()
 	return firstYear.NetIncome / preRetirementIncome
 }
 
+// calculateSustainableWithdrawalRate calculates the first-year withdrawal rate that, raised with
+// inflation each year, exactly depletes the TSP at the projection end. It annuitizes the balance
+// at the real return over the projection horizon.
+func (c *Calculator) calculateSustainableWithdrawalRate() float64 {
+	years := float64(projectionEndAge - c.calculateAgeAtRetirement() + 1)
+	if years <= 0 {
+		return 0
+	}
+	
+	realReturn := (1+c.growthRate())/(1+c.inflationRate()) - 1
+	if math.Abs(realReturn) < 1e-9 {
+		return 1 / years
+	}
+	return realReturn / (1 - math.Pow(1+realReturn, -years))
+}
+
+// FICA and retirement contribution rates withheld from federal pay
+const (
+	socialSecurityTaxRate  = 0.062
+	socialSecurityWageBase = 176100.0 // 2025
+	medicareTaxRate        = 0.0145
+	fersContributionRate   = 0.008
+	csrsContributionRate   = 0.07
+)
+
+// calculateWorkingNetIncome estimates take-home pay in the final working year, for a
+// like-for-like comparison with retirement net income. Salary is reduced by FICA, the
+// FERS or CSRS contribution, Traditional TSP contributions, the current FEHB premium, and
+// income taxes; TSP contributions and FEHB premiums are pre-tax. CSRS employees pay only Medicare.
+func (c *Calculator) calculateWorkingNetIncome() float64 {
+	salary := c.config.Employment.FinalSalary
+	if salary == 0 {
+		salary = c.high3Salary()
+	}
+	
+	fica := math.Min(salary, socialSecurityWageBase)*socialSecurityTaxRate + salary*medicareTaxRate
+	retirementContribution := salary * fersContributionRate
+	if c.config.Personal.RetirementSystem == "CSRS" {
+		fica = salary * medicareTaxRate
+		retirementContribution = salary * csrsContributionRate
+	}
+	
+	startAge := c.calculateAgeAtRetirement()
+	tspContribution := salary * c.config.Employment.TSPContributionRate
+	fehb := c.config.HealthInsurance.CurrentPremium
+	if fehb == 0 {
+		fehb = c.calculateHealthInsurance(startAge)
+	}
+	
+	wages := salary - tspContribution - fehb
+	working := models.AnnualProjection{SalaryIncome: wages, GrossIncome: wages}
+	federalTax := c.calculateFederalTax(working, startAge-1)
+	stateTax := c.calculateStateTax(working, startAge-1)
+	
+	return salary - fica - retirementContribution - tspContribution - fehb - federalTax - stateTax
+}
+
+// replacementRatioBasis returns the configured replacement ratio basis, net_high3 by default
+func (c *Calculator) replacementRatioBasis() string {
+	if basis := c.config.Retirement.ReplacementRatioBasis; basis != "" {
+		return basis
+	}
+	return "net_high3"
+}
+
 // findTSPDepletionAge finds when TSP balance reaches zero
 func (c *Calculator) findTSPDepletionAge(projections []models.AnnualProjection) int {
 	for _, p := range projections {
@@ -82,17 +324,226 @@ func (c *Calculator) findTSPDepletionAge(projections []models.AnnualProjection)
 	return 0 // TSP doesn't deplete within projection period
 }
 
+// buildTimeline lists the first projection year each income source starts and stops,
+// along with RMDs beginning and the retiree's death
+func (c *Calculator) buildTimeline(projections []models.AnnualProjection) []models.TimelineEvent {
+	var timeline []models.TimelineEvent
+	add := func(p models.AnnualProjection, event string) {
+		timeline = append(timeline, models.TimelineEvent{Age: p.Age, Year: p.Year, Event: event})
+	}
+
+	// source reports the first year an income is paid and, when it stops within the
+	// projection, the first year after it is last paid
+	source := func(start, stop string, paid func(models.AnnualProjection) bool) {
+		first, last := -1, -1
+		for i, p := range projections {
+			if paid(p) {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+		if first < 0 {
+			return
+		}
+		add(projections[first], start)
+		if stop != "" && last+1 < len(projections) {
+			add(projections[last+1], stop)
+		}
+	}
+
+	source("Pension begins", "", func(p models.AnnualProjection) bool {
+		return p.PensionIncome > 0 && !p.RetireeDeceased
+	})
+	source("FERS supplement begins", "FERS supplement ends", func(p models.AnnualProjection) bool {
+		return p.FERSSupplementIncome > 0
+	})
+	source("Social Security begins", "", func(p models.AnnualProjection) bool {
+		return p.SocialSecurityIncome > 0
+	})
+	source("Spouse's Social Security begins", "", func(p models.AnnualProjection) bool {
+		return p.SpousalSSIncome > 0
+	})
+
+	for _, p := range projections {
+		if p.RMD > 0 {
+			add(p, "Required minimum distributions begin")
+			break
+		}
+	}
+	for _, p := range projections {
+		if p.RetireeDeceased {
+			event := "Retiree dies"
+			if p.PensionIncome > 0 {
+				event += "; survivor annuity begins"
+			}
+			add(p, event)
+			break
+		}
+	}
+	if age := c.findTSPDepletionAge(projections); age > 0 {
+		for _, p := range projections {
+			if p.Age == age {
+				add(p, "TSP depleted")
+			}
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].Age < timeline[j].Age })
+	return timeline
+}
+
+// findIncomeGap finds the first run of years before Social Security starts without the FERS supplement
+// The dip is the drop in gross income from the year before the gap to its first year.
+func (c *Calculator) findIncomeGap(projections []models.AnnualProjection) (int, int, float64) {
+	for i, p := range projections {
+		if !p.IncomeGap {
+			continue
+		}
+
+		years := 0
+		for _, g := range projections[i:] {
+			if !g.IncomeGap {
+				break
+			}
+			years++
+		}
+
+		var dip float64
+		if i > 0 {
+			dip = math.Max(projections[i-1].GrossIncome-p.GrossIncome, 0)
+		}
+		return p.Age, years, dip
+	}
+	return 0, 0, 0
+}
+
+// ssEarliestClaimingAge is the earliest age retirement benefits can be claimed
+const ssEarliestClaimingAge = 62
+
+// findSupplementGap finds the years between the FERS supplement ending and Social Security
+// starting, and the supplement income not replaced over them, when claiming later than 62
+func (c *Calculator) findSupplementGap(projections []models.AnnualProjection) (int, float64) {
+	if c.config.SocialSecurity.ClaimingAge <= ssEarliestClaimingAge {
+		return 0, 0
+	}
+	
+	for i := 1; i < len(projections); i++ {
+		supplement := projections[i-1].FERSSupplementIncome
+		if supplement == 0 || projections[i].FERSSupplementIncome > 0 {
+			continue
+		}
+		
+		years := 0
+		for _, p := range projections[i:] {
+			if !p.IncomeGap {
+				break
+			}
+			years++
+		}
+		return years, supplement * float64(years)
+	}
+	return 0, 0
+}
+
+// dataQualityNotes lists the headline numbers that depend on estimated inputs
+func (c *Calculator) dataQualityNotes() []string {
+	var notes []string
+	confidence := c.config.Confidence
+
+	if confidence.High3Salary == "estimate" {
+		notes = append(notes, "Pension is based on an estimated high-3 salary")
+	}
+
+	ss := c.config.SocialSecurity
+	if _, ok := ss.MonthlyEstimates[ss.ClaimingAge]; !ok {
+		notes = append(notes, fmt.Sprintf(
+			"Social Security is estimated from the PIA with a simplified claiming-age adjustment; add your SSA statement estimate for age %d",
+			ss.ClaimingAge))
+	} else if confidence.EstimatedPIA == "estimate" {
+		notes = append(notes, "Social Security is based on an estimated benefit")
+	}
+
+	if confidence.TSPBalance == "estimate" {
+		notes = append(notes, "TSP withdrawals and balances start from an estimated balance")
+	}
+
+	if confidence.HealthPremium == "estimate" {
+		notes = append(notes, "Net income uses an estimated health insurance premium")
+	}
+
+	return notes
+}
+
+// fehbPremiumTolerance is how far the retirement FEHB premium may exceed the active premium before warning
+const fehbPremiumTolerance = 0.25
+
+// defaultPlausibleTSPBalance is the total TSP balance above which a balance is likely a typo
+const defaultPlausibleTSPBalance = 3000000.0
+
+// ssEstimateTolerance is how far the full-retirement-age estimate may differ from the PIA before warning
+const ssEstimateTolerance = 0.05
+
+// serviceReconciliationTolerance is how far total_years may differ from the service its
+// pieces add up to before warning
+const serviceReconciliationTolerance = 1.0
+
+// recurringFirstYear returns the first projection year without its one-time annual leave
+// payout and the tax the payout added, so first-year income and replacement ratios reflect
+// what recurs
+func (c *Calculator) recurringFirstYear(projection models.AnnualProjection) models.AnnualProjection {
+	recurring := projection
+	recurring.AnnualLeavePayout = 0
+	recurring.GrossIncome = calculateGrossIncome(recurring)
+	recurring.FederalTax = c.calculateFederalTax(recurring, projection.Age)
+	recurring.StateTax = c.calculateStateTax(recurring, projection.Age)
+	recurring.TotalDeductions -= projection.FederalTax - recurring.FederalTax + projection.StateTax - recurring.StateTax
+	recurring.NetIncome = recurring.GrossIncome - recurring.TotalDeductions
+	return recurring
+}
+
 // generateWarnings generates calculation warnings
 func (c *Calculator) generateWarnings() []string {
 	var warnings []string
 
 	// Check eligibility
-	if !c.checkRetirementEligibility() {
+	if !c.CheckRetirementEligibility() {
 		warnings = append(warnings, "Retirement eligibility requirements may not be met")
 	}
 
+	// A past retirement date is either an existing retiree or a typo
+	if c.isAlreadyRetired() {
+		warnings = append(warnings, fmt.Sprintf(
+			"Retirement date %s is in the past; projecting as already retired from age %d in %d with the entered balances as current. Check target_retirement_date if this is a typo",
+			c.config.Retirement.TargetRetirementDate.Format("2006-01-02"), c.projectionStartAge(),
+			c.config.Personal.BirthDate.Year()+c.projectionStartAge()))
+	}
+
 	// Note: TSP balance is now calculated as traditional + roth
 
+	// Check the TSP balance for an implausible value, such as an extra zero
+	tspBalance := c.config.TSP.TraditionalBalance + c.config.TSP.RothBalance
+	limit := c.config.TSP.PlausibleBalanceLimit
+	if limit == 0 {
+		limit = defaultPlausibleTSPBalance
+	}
+	if tspBalance > limit {
+		warnings = append(warnings, fmt.Sprintf(
+			"TSP balance of $%.0f exceeds the plausibility limit of $%.0f; check for a typo", tspBalance, limit))
+	}
+	if c.config.TSP.WithdrawalStrategy == "percentage" {
+		if sustainable := c.calculateSustainableWithdrawalRate(); c.config.TSP.WithdrawalRate > sustainable {
+			warnings = append(warnings, fmt.Sprintf(
+				"TSP withdrawal rate of %.1f%% exceeds the sustainable rate of %.1f%% for growth, inflation, and the horizon to age %d",
+				c.config.TSP.WithdrawalRate*100, sustainable*100, projectionEndAge))
+		}
+	}
+	if c.config.TSP.WithdrawalStrategy == "fixed_amount" && c.config.TSP.WithdrawalAmount > tspBalance {
+		warnings = append(warnings, fmt.Sprintf(
+			"TSP withdrawal amount ($%.0f) exceeds the total TSP balance ($%.0f)", c.config.TSP.WithdrawalAmount, tspBalance))
+	}
+
 	// Check if High-3 seems low
 	if c.config.Employment.High3Salary < 50000 {
 		warnings = append(warnings, "High-3 salary appears to be quite low")
@@ -103,82 +554,164 @@ func (c *Calculator) generateWarnings() []string {
 		warnings = append(warnings, "Early retirement will result in reduced pension benefits")
 	}
 
+	// Wages after retiring only feed the supplement's earnings test
+	if earnings := c.config.Retirement.SupplementEarnings; earnings > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"supplement_earnings of $%.0f reduces the FERS supplement but is not included in projected income or taxes",
+			earnings))
+	}
+
+	// Explain why a deferred or postponed FERS annuity gets no supplement
+	if c.config.Personal.RetirementSystem == "FERS" && c.calculateAgeAtRetirement() < 62 && c.isDeferredOrPostponed() {
+		warnings = append(warnings, "No FERS annuity supplement: it is only paid with an immediate annuity, not a deferred or postponed MRA+10 retirement")
+	}
+
+	// Check Social Security against the statutory maximum benefit
+	if ss := c.calculateSocialSecurity(); ss.Capped {
+		warnings = append(warnings, fmt.Sprintf(
+			"Social Security benefit capped at the statutory maximum of $%.0f/month for claiming at age %d; check the estimated PIA",
+			ss.MonthlyBenefit, ss.ClaimingAge))
+	}
+
+	// Check the full-retirement-age estimate matches the PIA, which is defined as the FRA benefit
+	pia := c.config.SocialSecurity.EstimatedPIA
+	if estimate, ok := c.config.SocialSecurity.MonthlyEstimates[ssFullRetirementAge]; ok && pia > 0 &&
+		math.Abs(estimate-pia)/pia > ssEstimateTolerance {
+		warnings = append(warnings, fmt.Sprintf(
+			"Social Security estimate at full retirement age %d ($%.0f) differs from the PIA ($%.0f) by %.0f%%; they should match",
+			ssFullRetirementAge, estimate, pia, math.Abs(estimate-pia)/pia*100))
+	}
+
+	if warning := c.earningsPIAWarning(); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	// Check FEHB retirement premium against the active enrollee share
+	current := c.config.HealthInsurance.CurrentPremium
+	retirement := c.config.HealthInsurance.RetirementPremium
+	if current > 0 && retirement > current*(1+fehbPremiumTolerance) {
+		warnings = append(warnings, fmt.Sprintf(
+			"Retirement FEHB premium ($%.0f) is %.0f%% higher than the active premium ($%.0f); retirees normally pay the same enrollee share",
+			retirement, (retirement/current-1)*100, current))
+	}
+
+	// Check survivor election has a spouse or former spouse to benefit
+	if c.config.Retirement.SurvivorBenefit != "none" {
+		switch c.config.Personal.MaritalStatus {
+		case "single", "widowed":
+			warnings = append(warnings, "Survivor benefit elected without a spouse or former spouse; the pension reduction buys no benefit")
+		}
+	} else if c.config.Personal.MaritalStatus == "married" {
+		warnings = append(warnings, "No survivor annuity elected: your spouse loses FEHB eligibility at your death, since continuing FEHB requires a survivor annuity")
+	}
+
+	warnings = append(warnings, c.uncollectedSSWarnings()...)
+	if warning := c.serviceReconciliationWarning(); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
 	return warnings
 }
 
-// checkRetirementEligibility performs basic eligibility check
-func (c *Calculator) checkRetirementEligibility() bool {
-	age := c.calculateAgeAtRetirement()
-	service := c.config.Employment.CreditableService.TotalYears
+// uncollectedSSWarnings flags Social Security claiming ages that are never reached while
+// the benefit could be paid, which leaves the benefit out of the projection entirely
+func (c *Calculator) uncollectedSSWarnings() []string {
+	var warnings []string
+	ss := c.config.SocialSecurity
 
-	if c.config.Personal.RetirementSystem == "FERS" {
-		// Basic FERS eligibility
-		if age >= 62 && service >= 5 {
-			return true
-		}
-		if age >= 60 && service >= 20 {
-			return true
-		}
-		mra := c.calculateMRA()
-		if age >= mra && service >= 30 {
-			return true
-		}
-		if age >= mra && service >= 10 {
-			return true
-		}
-	} else {
-		// Basic CSRS eligibility
-		if age >= 62 && service >= 5 {
-			return true
-		}
-		if age >= 60 && service >= 20 {
-			return true
+	// The retiree's own and spousal benefits are paid through the projection end or until death
+	lastAge, horizon := projectionEndAge, "the projection ends"
+	if death := c.config.Personal.AssumedDeathAge; death > 0 && death <= lastAge {
+		lastAge, horizon = death-1, fmt.Sprintf("your assumed death at %d", death)
+	}
+
+	if ss.ClaimingAge > lastAge {
+		warnings = append(warnings, fmt.Sprintf(
+			"Social Security claiming age %d is after %s, so your own benefit is never collected; check claiming_age",
+			ss.ClaimingAge, horizon))
+	}
+	if spouse := ss.SpouseBenefit; spouse != nil {
+		// The retiree's age in the year the spouse reaches their claiming age
+		spouseClaimAge := spouse.ClaimingAge - c.spouseAge(0)
+		if max(spouseClaimAge, ss.ClaimingAge) > lastAge {
+			warnings = append(warnings, fmt.Sprintf(
+				"Spouse's Social Security claiming age %d is not reached before %s, so the spousal benefit is never collected; check spouse_benefit.claiming_age",
+				spouse.ClaimingAge, horizon))
 		}
-		if age >= 55 && service >= 30 {
-			return true
+	}
+
+	return warnings
+}
+
+// earningsPIAWarning flags an estimated_pia that disagrees with the PIA computed from the
+// earnings history, which replaces it when the config is loaded
+func (c *Calculator) earningsPIAWarning() string {
+	ss := c.config.SocialSecurity
+	if len(ss.EarningsHistory) == 0 {
+		return ""
+	}
+	supplied := ss.SuppliedPIA
+	if supplied == 0 {
+		supplied = ss.EstimatedPIA
+	}
+	derived := PIAFromEarnings(ss)
+	if supplied <= 0 || math.Abs(supplied-derived) < 0.1 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"estimated_pia of $%.0f is replaced by the $%.0f PIA computed from earnings_history; remove one of them",
+		supplied, derived)
+}
+
+// serviceReconciliationWarning flags a total_years that does not reconcile with the
+// calendar span from hire to retirement, less the unworked share of part-time periods,
+// plus bought-back military service
+func (c *Calculator) serviceReconciliationWarning() string {
+	service := c.config.Employment.CreditableService
+	span := ServiceYears(c.config.Employment.HireDate, c.config.Retirement.TargetRetirementDate)
+
+	// A loaded file keeps its total_years aside before the loader derives it from the
+	// span; a configuration built in code carries it in TotalYears, where a total equal
+	// to the span was derived and has nothing to reconcile
+	supplied := service.SuppliedTotalYears
+	if supplied == 0 {
+		supplied = service.TotalYears
+		if math.Abs(supplied-span) < 0.05 {
+			return ""
 		}
 	}
 
-	return false
+	var partTime float64
+	for _, period := range service.PartTimePeriods {
+		years := ServiceYears(period.StartDate, period.EndDate)
+		partTime += years * (1 - period.HoursPerWeek/40)
+	}
+	military := c.militaryCreditYears()
+	computed := span - partTime + military
+
+	if math.Abs(supplied-computed) <= serviceReconciliationTolerance {
+		return ""
+	}
+	return fmt.Sprintf(
+		"total_years of %.1f does not reconcile with the %.1f years computed from service (%.1f calendar years, -%.1f part-time, +%.1f military); check creditable_service",
+		supplied, computed, span, partTime, military)
+}
+
+// CheckRetirementEligibility reports whether the annuity is payable when it begins
+// under one of the immediate retirement rules, using the age in months at commencement
+// and the service credited to the annuity, including redeposited refunded service.
+func (c *Calculator) CheckRetirementEligibility() bool {
+	return c.meetsRetirementRule(c.commencementAgeMonths(), c.annuityServiceYears())
 }
 
 // CompareRetirementAges compares multiple retirement ages
 func CompareRetirementAges(baseConfig *models.Config, ageStrings []string) (*models.ComparisonResults, error) {
-	var results []models.RetirementResults
-	
+	scenarios := make([][]ScenarioOverride, 0, len(ageStrings))
 	for _, ageStr := range ageStrings {
-		age, err := strconv.Atoi(ageStr)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Create a copy of the config with modified retirement date
-		configCopy := *baseConfig
-		
-		// Calculate new retirement date based on age
-		birthYear := configCopy.Personal.BirthDate.Year()
-		retirementYear := birthYear + age
-		configCopy.Retirement.TargetRetirementDate = time.Date(retirementYear, 
-			configCopy.Personal.BirthDate.Month(), 
-			configCopy.Personal.BirthDate.Day(), 0, 0, 0, 0, time.UTC)
-		
-		// Calculate results for this age
-		calc := NewCalculator(&configCopy)
-		result, err := calc.Calculate()
-		if err != nil {
-			return nil, err
-		}
-		
-		results = append(results, *result)
-	}
-	
-	// Create comparison
-	comparison := &models.ComparisonResults{
-		Scenarios:    results,
-		ComparisonMetrics: calculateComparisonMetrics(results),
+		scenarios = append(scenarios, []ScenarioOverride{{Name: "age", Value: ageStr}})
 	}
 	
-	return comparison, nil
+	return compareScenarios(baseConfig, scenarios)
 }
 
 // calculateComparisonMetrics calculates comparison metrics
@@ -0,0 +1,88 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+)
+
+// pensionVerifyTolerance absorbs rounding differences between the two annuity computations
+const pensionVerifyTolerance = 0.01
+
+// VerifyPension recomputes the annual annuity by following OPM's plain-English steps,
+// independently of calculatePension, and reports an error if the result differs from
+// the calculated annuity. Transfers and CSRS deposit reductions are not covered by the
+// published steps and are not checked.
+func (c *Calculator) VerifyPension(annualPension float64) error {
+	service := c.annuityServiceYears()
+	if c.config.Employment.CreditableService.Transfer != nil || c.calculateDepositReduction() > 0 {
+		return nil
+	}
+
+	expected := c.opmAnnuity(service, c.high3Salary())
+	if math.Abs(expected-annualPension) > pensionVerifyTolerance {
+		return fmt.Errorf("pension verification failed: calculated annuity $%.2f differs from OPM formula $%.2f",
+			annualPension, expected)
+	}
+	return nil
+}
+
+// opmAnnuity follows OPM's steps: high-3 times years of service times the accrual factor,
+// less the age reduction, less the survivor election reduction
+func (c *Calculator) opmAnnuity(service, high3 float64) float64 {
+	separationAge := c.calculateAgeAtRetirement()
+	startAge := separationAge
+	if c.config.Retirement.DeferredStartAge > startAge {
+		startAge = c.config.Retirement.DeferredStartAge
+	}
+
+	var annuity, reduction float64
+	if c.config.Personal.RetirementSystem == "FERS" {
+		// 1% per year of service, or 1.1% when separating at 62 or later with 20 or more years
+		factor := 0.01
+		if separationAge >= 62 && service >= 20 {
+			factor = 0.011
+		}
+		annuity = high3 * service * factor
+		if c.meetsSpecialProvision(separationAge, service) {
+			// Special provision: 1.7% for the first 20 years and 1% for each year after
+			annuity = high3 * (0.017*math.Min(service, specialProvisionService) +
+				0.01*math.Max(service-specialProvisionService, 0))
+		}
+
+		// MRA+10 retirements lose 5% for each year under 62, unless 30 years or 60 with 20
+		unreduced := startAge >= 62 || (startAge >= 60 && service >= 20) ||
+			(c.reachesMRA() && service >= 30) || c.meetsSpecialProvision(startAge, service)
+		if !unreduced && c.reachesMRA() && service >= 10 {
+			reduction = 0.05 * float64(62-startAge)
+		}
+	} else {
+		// 1.5% for the first 5 years, 1.75% for the next 5, and 2% for every year after,
+		// up to 80% of high-3
+		annuity = high3 * math.Min(0.015*math.Min(service, 5)+
+			0.0175*math.Max(math.Min(service-5, 5), 0)+
+			0.02*math.Max(service-10, 0), 0.80)
+		if c.meetsSpecialProvision(separationAge, service) {
+			// Special provision: 2.5% for the first 20 years and 2% for each year after
+			annuity = high3 * math.Min(0.025*math.Min(service, specialProvisionService)+
+				0.02*math.Max(service-specialProvisionService, 0), 0.80)
+		}
+
+		// Retirements before 62 with 20 to 29 years lose 2% for each year under 62, up to 25%
+		unreduced := startAge >= 62 || (startAge >= 60 && service >= 20) || (startAge >= 55 && service >= 30) ||
+			c.meetsSpecialProvision(startAge, service)
+		if !unreduced && startAge >= 55 && service >= 20 {
+			reduction = math.Min(0.02*float64(62-startAge), 0.25)
+		}
+	}
+	annuity *= 1 - reduction
+
+	// The survivor election reduces the annuity: 10% (full) or 5% (partial) under FERS;
+	// under CSRS 2.5% of the first $3,600 of the base plus 10% of the rest, halved for partial
+	survivorFactor := map[string]float64{"full": 1, "partial": 0.5}[c.config.Retirement.SurvivorBenefit]
+	if c.config.Personal.RetirementSystem == "FERS" {
+		annuity -= annuity * 0.10 * survivorFactor
+	} else {
+		annuity -= (0.025*math.Min(annuity, 3600) + 0.10*math.Max(annuity-3600, 0)) * survivorFactor
+	}
+	return annuity
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"rgehrsitz/ferex_cli/internal/models"
+	"rgehrsitz/ferex_cli/pkg/calc"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
@@ -13,47 +19,182 @@ import (
 
 var validate *validator.Validate
 
+// Error categories; match with errors.Is
+var (
+	// ErrLoad reports a config file that could not be read, parsed, or completed
+	ErrLoad = errors.New("failed to load config")
+	// ErrValidation reports a config with a bad setting: an unknown key or assumption
+	// profile, a mistyped value, or a failed field or business rule check
+	ErrValidation = errors.New("config validation failed")
+)
+
+// categorizedError tags an error with one of the category sentinels without adding the
+// sentinel's text to the message, so callers can add their own context
+type categorizedError struct {
+	category error
+	err      error
+}
+
+func (e *categorizedError) Error() string { return e.err.Error() }
+
+func (e *categorizedError) Unwrap() []error { return []error{e.category, e.err} }
+
+// withCategory tags err with category
+func withCategory(category, err error) error {
+	return &categorizedError{category: category, err: err}
+}
+
 func init() {
 	validate = validator.New()
 }
 
+// AssumptionProfile holds the rates supplied by a named assumption profile
+type AssumptionProfile struct {
+	GrowthRate    float64
+	InflationRate float64
+	COLARate      float64
+}
+
+// assumptionProfiles maps profile names to their TSP growth, inflation, and COLA rates
+var assumptionProfiles = map[string]AssumptionProfile{
+	"optimistic":  {GrowthRate: 0.08, InflationRate: 0.02, COLARate: 0.025},
+	"base":        {GrowthRate: 0.07, InflationRate: 0.025, COLARate: 0.025},
+	"pessimistic": {GrowthRate: 0.05, InflationRate: 0.035, COLARate: 0.015},
+}
+
+// LookupProfile returns the named assumption profile
+func LookupProfile(name string) (AssumptionProfile, error) {
+	profile, ok := assumptionProfiles[name]
+	if !ok {
+		return AssumptionProfile{}, fmt.Errorf("unknown assumption profile: %s", name)
+	}
+	return profile, nil
+}
+
+// LoadOptions controls how a configuration file is loaded
+type LoadOptions struct {
+	// Profile selects a named assumption profile before defaults are filled; empty keeps
+	// the one set in the file
+	Profile string
+	// AsOf evaluates the plan as of this date instead of today; zero keeps the file's as_of
+	AsOf time.Time
+	// Notes receives informational messages such as migration notes; nil discards them
+	Notes io.Writer
+}
+
 // LoadConfig loads and validates a configuration file
 func LoadConfig(filename string) (*models.Config, error) {
+	return LoadConfigWithOptions(filename, LoadOptions{})
+}
+
+// LoadConfigWithProfile loads a configuration file, selecting the named assumption
+// profile before defaults are filled. An empty profile keeps the one set in the file.
+func LoadConfigWithProfile(filename, profile string) (*models.Config, error) {
+	return LoadConfigWithOptions(filename, LoadOptions{Profile: profile})
+}
+
+// LoadConfigWithOptions loads a configuration file as directed by opts
+func LoadConfigWithOptions(filename string, opts LoadOptions) (*models.Config, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, withCategory(ErrLoad, fmt.Errorf("failed to read config file: %w", err))
+	}
+
+	// Upgrade older config versions before decoding
+	doc, notes, err := migrateConfig(filename, data)
+	if err != nil {
+		return nil, withCategory(ErrLoad, fmt.Errorf("failed to migrate config: %w", err))
+	}
+	if opts.Notes != nil {
+		for _, note := range notes {
+			fmt.Fprintf(opts.Notes, "Note: %s\n", note)
+		}
 	}
 
+	// Check keys against the file as written so reported line numbers match it; the
+	// migration only adds known keys
 	var config models.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := decodeStrict(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
+	if len(notes) > 0 {
+		config = models.Config{}
+		if err := doc.Decode(&config); err != nil {
+			return nil, withCategory(ErrLoad, fmt.Errorf("failed to parse YAML: %w", err))
+		}
+	}
+
+	if opts.Profile != "" {
+		config.Assumptions.Profile = opts.Profile
+	}
+	if !opts.AsOf.IsZero() {
+		config.Assumptions.AsOf = opts.AsOf
+	}
+	// An unknown profile is a bad setting rather than an unreadable file
+	if config.Assumptions.Profile != "" {
+		if _, err := LookupProfile(config.Assumptions.Profile); err != nil {
+			return nil, withCategory(ErrValidation, err)
+		}
+	}
+
+	// Keep any total_years given in the file before it is recomputed, so it can be
+	// reconciled against the service it should add up to
+	config.Employment.CreditableService.SuppliedTotalYears = config.Employment.CreditableService.TotalYears
+	config.SocialSecurity.SuppliedPIA = config.SocialSecurity.EstimatedPIA
 
 	// Fill in calculated fields if missing
 	if err := fillCalculatedFields(&config); err != nil {
-		return nil, fmt.Errorf("failed to calculate derived fields: %w", err)
+		return nil, withCategory(ErrLoad, fmt.Errorf("failed to calculate derived fields: %w", err))
 	}
 
 	return &config, nil
 }
 
+// unknownFieldPattern matches yaml.v3's report of a key with no matching struct field
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type \S+$`)
+
+// decodeStrict decodes YAML into config, rejecting keys that match no config field so a
+// misspelled key is reported instead of silently falling back to a default
+// Unknown keys and mistyped values are validation errors; malformed YAML is a load error.
+func decodeStrict(data []byte, config *models.Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(config)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil // An empty file decodes to the zero config, as with yaml.Unmarshal
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return withCategory(ErrLoad, err)
+	}
+	messages := make([]string, len(typeErr.Errors))
+	for i, message := range typeErr.Errors {
+		if match := unknownFieldPattern.FindStringSubmatch(message); match != nil {
+			message = fmt.Sprintf("line %s: unknown field %q (misspelled or misplaced key?)", match[1], match[2])
+		}
+		messages[i] = message
+	}
+	return withCategory(ErrValidation, errors.New(strings.Join(messages, "; ")))
+}
+
 // ValidateConfig validates a configuration struct
 func ValidateConfig(config *models.Config) error {
 	if err := validate.Struct(config); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+		return withCategory(ErrValidation, err)
 	}
 
 	// Custom validation logic
 	if err := validateBusinessRules(config); err != nil {
-		return fmt.Errorf("business rule validation failed: %w", err)
+		return withCategory(ErrValidation, fmt.Errorf("business rule validation failed: %w", err))
 	}
 
 	return nil
 }
 
-// ValidateConfigFile validates a configuration file
-func ValidateConfigFile(filename string, fixInteractive bool) error {
-	config, err := LoadConfig(filename)
+// ValidateConfigFile validates a configuration file, loading it as directed by opts
+func ValidateConfigFile(filename string, fixInteractive bool, opts LoadOptions) error {
+	config, err := LoadConfigWithOptions(filename, opts)
 	if err != nil {
 		return err
 	}
@@ -65,33 +206,43 @@ func ValidateConfigFile(filename string, fixInteractive bool) error {
 		return err
 	}
 
-	fmt.Printf("✓ Configuration file %s is valid\n", filename)
 	return nil
 }
 
 // GenerateTemplate generates a configuration template
+// total_years is left out: it is derived from the dates on load, and a value written to
+// the file would be reconciled against them.
 func GenerateTemplate(templateType string) (*models.Config, error) {
+	var config *models.Config
 	switch templateType {
 	case "basic":
-		return generateBasicTemplate(), nil
+		config = generateBasicTemplate()
 	case "advanced":
-		return generateAdvancedTemplate(), nil
+		config = generateAdvancedTemplate()
 	case "csrs":
-		return generateCSRSTemplate(), nil
+		config = generateCSRSTemplate()
 	default:
 		return nil, fmt.Errorf("unknown template type: %s", templateType)
 	}
+	config.Employment.CreditableService.TotalYears = 0
+	return config, nil
 }
 
 // fillCalculatedFields fills in calculated fields that may be missing
 func fillCalculatedFields(config *models.Config) error {
 	// Always calculate total years of service from hire date to target retirement date
-	serviceYears := calculateServiceYears(config.Employment.HireDate, config.Retirement.TargetRetirementDate)
+	serviceYears := calc.ServiceYears(config.Employment.HireDate, config.Retirement.TargetRetirementDate)
 	config.Employment.CreditableService.TotalYears = serviceYears
 
-	// Set default TSP growth rate if not provided
-	if config.TSP.GrowthRate == 0 {
-		config.TSP.GrowthRate = 0.07 // 7% default
+	// Fill unset rates from the assumption profile (base by default)
+	if err := applyAssumptionProfile(config); err != nil {
+		return err
+	}
+	
+	// Derive the PIA from the earnings history when one is given; the calculator warns
+	// when it replaces a different estimated_pia from the file
+	if len(config.SocialSecurity.EarningsHistory) > 0 {
+		config.SocialSecurity.EstimatedPIA = calc.PIAFromEarnings(config.SocialSecurity)
 	}
 	
 	// Set default withdrawal rate for percentage strategy
@@ -99,14 +250,45 @@ func fillCalculatedFields(config *models.Config) error {
 		config.TSP.WithdrawalRate = 0.04 // 4% default
 	}
 	
+	// Default phased retirement salary to a half-time schedule
+	if phased := config.Retirement.PhasedRetirement; phased != nil && phased.PartTimeSalary == 0 {
+		phased.PartTimeSalary = config.Employment.High3Salary * 0.5
+	}
+	
 	// Set default health insurance COLA
-	if config.HealthInsurance.PremiumCOLA == 0 && config.HealthInsurance.RetirementPremium > 0 {
+	if config.HealthInsurance.PremiumCOLA == 0 && (config.HealthInsurance.RetirementPremium > 0 || config.HealthInsurance.CurrentPremium > 0) {
 		config.HealthInsurance.PremiumCOLA = 0.03 // 3% default
 	}
 
 	return nil
 }
 
+// applyAssumptionProfile fills unset growth, inflation, and COLA rates from the selected profile
+// Rates already present in the config are explicit overrides and are left untouched.
+func applyAssumptionProfile(config *models.Config) error {
+	name := config.Assumptions.Profile
+	if name == "" {
+		name = "base"
+	}
+
+	profile, err := LookupProfile(name)
+	if err != nil {
+		return err
+	}
+
+	if config.TSP.GrowthRate == nil {
+		config.TSP.GrowthRate = models.Rate(profile.GrowthRate)
+	}
+	if config.Assumptions.InflationRate == nil {
+		config.Assumptions.InflationRate = models.Rate(profile.InflationRate)
+	}
+	if config.Assumptions.COLARate == nil {
+		config.Assumptions.COLARate = models.Rate(profile.COLARate)
+	}
+
+	return nil
+}
+
 // validateBusinessRules validates business logic rules
 // Optional fields (like early_retirement) may be omitted from the config YAML.
 func validateBusinessRules(config *models.Config) error {
@@ -133,10 +315,73 @@ func validateBusinessRules(config *models.Config) error {
 		if config.TSP.WithdrawalAmount > 0 {
 			return fmt.Errorf("withdrawal_amount should be zero for percentage strategy")
 		}
+	case "custom_schedule":
+		if len(config.TSP.WithdrawalSchedule) == 0 {
+			return fmt.Errorf("custom_schedule strategy requires a withdrawal_schedule")
+		}
+	}
+
+	if config.TSP.WithdrawalCOLA > 0 && config.TSP.WithdrawalStrategy != "fixed_amount" {
+		return fmt.Errorf("withdrawal_cola applies only to the fixed_amount strategy")
+	}
+	if config.TSP.WithdrawalBasis == "net" && config.TSP.WithdrawalStrategy != "fixed_amount" {
+		return fmt.Errorf("withdrawal_basis net applies only to the fixed_amount strategy")
+	}
+
+	if config.TSP.WithdrawalCeiling > 0 && config.TSP.WithdrawalFloor > config.TSP.WithdrawalCeiling {
+		return fmt.Errorf("withdrawal_floor cannot exceed withdrawal_ceiling")
+	}
+
+	// The bracket to fill must be one with a top, so not the 37% bracket
+	switch config.TSP.BracketFillRate {
+	case 0, 0.10, 0.12, 0.22, 0.24, 0.32, 0.35:
+	default:
+		return fmt.Errorf("bracket_fill_rate %.2f is not a federal bracket rate (0.10, 0.12, 0.22, 0.24, 0.32, or 0.35)",
+			config.TSP.BracketFillRate)
+	}
+
+	// Check transferee service split
+	if transfer := config.Employment.CreditableService.Transfer; transfer != nil {
+		if config.Personal.RetirementSystem != "FERS" {
+			return fmt.Errorf("transfer service applies only to FERS transferees")
+		}
+		if transfer.CSRSYears >= config.Employment.CreditableService.TotalYears {
+			return fmt.Errorf("transfer csrs_years must be less than total creditable service")
+		}
+	}
+
+	// Check custom state tax brackets are in ascending order
+	customRules := []*models.CustomStateTax{config.TaxInfo.CustomStateTax}
+	for _, change := range config.TaxInfo.ResidenceChanges {
+		customRules = append(customRules, change.CustomStateTax)
+	}
+	for _, rule := range customRules {
+		if rule == nil {
+			continue
+		}
+		for i := 1; i < len(rule.Brackets); i++ {
+			if rule.Brackets[i].Over <= rule.Brackets[i-1].Over {
+				return fmt.Errorf("custom_state_tax brackets must be in ascending order of over")
+			}
+		}
+	}
+
+	// Check refunded service predates the current appointment so it is not counted twice
+	for _, period := range config.Employment.CreditableService.RefundedService {
+		if !period.EndDate.After(period.StartDate) {
+			return fmt.Errorf("refunded service end_date must be after start_date")
+		}
+		if period.EndDate.After(config.Employment.HireDate) {
+			return fmt.Errorf("refunded service must end before the hire date")
+		}
 	}
 
 	// Check dates are logical
-	if config.Employment.HireDate.After(time.Now()) {
+	asOf := config.Assumptions.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	if config.Employment.HireDate.After(asOf) {
 		return fmt.Errorf("hire date cannot be in the future")
 	}
 
@@ -151,56 +396,18 @@ func validateBusinessRules(config *models.Config) error {
 	return nil
 }
 
-// validateFERSEligibility validates FERS retirement eligibility
+// validateFERSEligibility validates FERS retirement eligibility using the calculator's rules
 func validateFERSEligibility(config *models.Config) error {
-	age := calculateAgeAtDate(config.Personal.BirthDate, config.Retirement.TargetRetirementDate)
-	service := config.Employment.CreditableService.TotalYears
-
-	// Check basic eligibility scenarios
-	if age >= 62 && service >= 5 {
-		return nil // Age 62 with 5+ years
-	}
-	if age >= 60 && service >= 20 {
-		return nil // Age 60 with 20+ years
-	}
-	if service >= 30 {
-		// MRA + 30 years (MRA varies by birth year)
-		mra := calculateMRA(config.Personal.BirthDate)
-		if age >= mra {
-			return nil
-		}
-	}
-	if service >= 10 {
-		// MRA + 10 years (with reduction)
-		mra := calculateMRA(config.Personal.BirthDate)
-		if age >= mra {
-			return nil
-		}
+	if calc.NewCalculator(config).CheckRetirementEligibility() {
+		return nil
 	}
 
-	return fmt.Errorf("FERS eligibility not met: age %d with %.1f years of service", age, service)
-}
-
-// calculateMRA calculates Minimum Retirement Age based on birth year
-func calculateMRA(birthDate time.Time) int {
-	birthYear := birthDate.Year()
-	
-	switch {
-	case birthYear < 1948:
-		return 55
-	case birthYear < 1953:
-		// 1948-1952: increases from 55 to 56 gradually, simplified to 56 for 1950+
-		if birthYear < 1950 {
-			return 55
-		}
-		return 56
-	case birthYear < 1965:
-		return 56
-	case birthYear < 1970:
-		return 57
-	default:
-		return 57
+	// A deferred annuity must be payable at the age it begins
+	age := calculateAgeAtDate(config.Personal.BirthDate, config.Retirement.TargetRetirementDate)
+	if config.Retirement.DeferredStartAge > age {
+		age = config.Retirement.DeferredStartAge
 	}
+	return fmt.Errorf("FERS eligibility not met: age %d with %.1f years of service", age, config.Employment.CreditableService.TotalYears)
 }
 
 // calculateAge calculates current age from birth date
@@ -217,13 +424,6 @@ func calculateAge(birthDate time.Time) int {
 	return age
 }
 
-// calculateServiceYears calculates years of service between hire and retirement dates
-func calculateServiceYears(hireDate, retirementDate time.Time) float64 {
-	duration := retirementDate.Sub(hireDate)
-	years := duration.Hours() / (24 * 365.25) // Account for leap years
-	return years
-}
-
 // calculateAgeAtDate calculates age at a specific date
 func calculateAgeAtDate(birthDate, targetDate time.Time) int {
 	years := targetDate.Year() - birthDate.Year()
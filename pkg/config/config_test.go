@@ -1,9 +1,18 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"rgehrsitz/ferex_cli/internal/models"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestGenerateBasicTemplate(t *testing.T) {
@@ -114,33 +123,12 @@ func TestFERSEligibilityValidation(t *testing.T) {
 	}
 }
 
-func TestMRACalculation(t *testing.T) {
-	testCases := []struct {
-		birthYear   int
-		expectedMRA int
-	}{
-		{1945, 55},
-		{1950, 56},
-		{1955, 56},
-		{1967, 57},
-		{1975, 57},
-	}
-	
-	for _, tc := range testCases {
-		birthDate := time.Date(tc.birthYear, 1, 1, 0, 0, 0, 0, time.UTC)
-		mra := calculateMRA(birthDate)
-		if mra != tc.expectedMRA {
-			t.Errorf("Birth year %d: expected MRA %d, got %d", tc.birthYear, tc.expectedMRA, mra)
-		}
-	}
-}
-
 func TestFillCalculatedFields(t *testing.T) {
 	cfg := generateBasicTemplate()
 	
 	// Clear calculated fields
 	cfg.Employment.CreditableService.TotalYears = 0
-	cfg.TSP.GrowthRate = 0
+	cfg.TSP.GrowthRate = nil
 	
 	err := fillCalculatedFields(cfg)
 	if err != nil {
@@ -152,7 +140,7 @@ func TestFillCalculatedFields(t *testing.T) {
 		t.Error("Total service years were not calculated")
 	}
 	
-	if cfg.TSP.GrowthRate != 0.07 {
+	if cfg.TSP.GrowthRate == nil || *cfg.TSP.GrowthRate != 0.07 {
 		t.Error("TSP growth rate was not set to default 7%")
 	}
 	
@@ -209,4 +197,255 @@ func TestCalculateAge(t *testing.T) {
 	if futureAge > 0 {
 		t.Errorf("Future birth date resulted in positive age: %d", futureAge)
 	}
-}
\ No newline at end of file
+}
+func TestAssumptionProfileDefaults(t *testing.T) {
+	cfg := generateBasicTemplate()
+	cfg.TSP.GrowthRate = nil
+	cfg.Assumptions.Profile = "pessimistic"
+
+	if err := fillCalculatedFields(cfg); err != nil {
+		t.Fatalf("fillCalculatedFields failed: %v", err)
+	}
+
+	if *cfg.TSP.GrowthRate != 0.05 || *cfg.Assumptions.InflationRate != 0.035 || *cfg.Assumptions.COLARate != 0.015 {
+		t.Errorf("Expected pessimistic rates 5%%/3.5%%/1.5%%, got %.3f/%.3f/%.3f",
+			*cfg.TSP.GrowthRate, *cfg.Assumptions.InflationRate, *cfg.Assumptions.COLARate)
+	}
+
+	// Explicit rates take precedence over the profile, including an explicit 0
+	cfg = generateBasicTemplate()
+	cfg.Assumptions.Profile = "pessimistic"
+	cfg.Assumptions.COLARate = models.Rate(0.02)
+	cfg.Assumptions.InflationRate = models.Rate(0)
+
+	if err := fillCalculatedFields(cfg); err != nil {
+		t.Fatalf("fillCalculatedFields failed: %v", err)
+	}
+
+	if *cfg.TSP.GrowthRate != 0.07 {
+		t.Errorf("Expected explicit growth rate 7%% to be kept, got %.3f", *cfg.TSP.GrowthRate)
+	}
+	if *cfg.Assumptions.COLARate != 0.02 {
+		t.Errorf("Expected explicit COLA rate 2%% to be kept, got %.3f", *cfg.Assumptions.COLARate)
+	}
+	if *cfg.Assumptions.InflationRate != 0 {
+		t.Errorf("Expected explicit inflation rate 0%% to be kept, got %.3f", *cfg.Assumptions.InflationRate)
+	}
+
+	cfg.Assumptions.Profile = "unknown"
+	if err := fillCalculatedFields(cfg); err == nil {
+		t.Error("Expected error for unknown assumption profile")
+	}
+}
+
+func TestSurvivorSSClaimingAgeAllowsSixty(t *testing.T) {
+	cfg := generateBasicTemplate()
+	cfg.SocialSecurity.SurvivorSSClaimingAge = 60
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected a survivor Social Security claim at 60 to be accepted, got %v", err)
+	}
+
+	cfg.SocialSecurity.ClaimingAge = 60
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected an own-benefit Social Security claim at 60 to be rejected")
+	}
+}
+
+func TestValidationErrorIsSentinel(t *testing.T) {
+	cfg := generateBasicTemplate()
+	cfg.Personal.RetirementSystem = "FERS-RAE"
+
+	err := ValidateConfig(cfg)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected errors.Is(err, ErrValidation) for a bad config, got %v", err)
+	}
+	if errors.Is(err, ErrLoad) {
+		t.Error("A validation failure should not match ErrLoad")
+	}
+
+	if _, err := LoadConfig("does-not-exist.yaml"); !errors.Is(err, ErrLoad) {
+		t.Errorf("Expected errors.Is(err, ErrLoad) for a missing file, got %v", err)
+	}
+}
+
+func TestLoadConfigMigratesVersion1(t *testing.T) {
+	v1 := `personal:
+  name: "Jane Doe"
+  birth_date: 1967-03-15T00:00:00Z
+  retirement_system: "FERS"
+employment:
+  hire_date: 1999-01-15T00:00:00Z
+  high_3_salary: 82000
+  creditable_service:
+    total_years: 25
+retirement:
+  target_retirement_date: 2029-03-15T00:00:00Z
+  survivor_benefit: "none"
+tsp:
+  traditional_balance: 400000
+  roth_balance: 100000
+  withdrawal_strategy: "life_expectancy"
+social_security:
+  estimated_pia: 2800
+  claiming_age: 67
+`
+	filename := filepath.Join(t.TempDir(), "v1.yaml")
+	if err := os.WriteFile(filename, []byte(v1), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var notes bytes.Buffer
+	cfg, err := LoadConfigWithOptions(filename, LoadOptions{Notes: &notes})
+	if err != nil {
+		t.Fatalf("Expected a version 1 config to load, got %v", err)
+	}
+	if !strings.Contains(notes.String(), "from config version 1 to 2") {
+		t.Errorf("Expected a migration note, got %q", notes.String())
+	}
+	if cfg.Version != CurrentConfigVersion {
+		t.Errorf("Expected version %d after migration, got %d", CurrentConfigVersion, cfg.Version)
+	}
+	if cfg.TSP.WithdrawalSource != "traditional" || cfg.Retirement.ReplacementRatioBasis != "net_high3" {
+		t.Errorf("Expected migrated defaults, got withdrawal_source %q and replacement_ratio_basis %q",
+			cfg.TSP.WithdrawalSource, cfg.Retirement.ReplacementRatioBasis)
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected the migrated config to validate, got %v", err)
+	}
+
+	future := filepath.Join(t.TempDir(), "future.yaml")
+	if err := os.WriteFile(future, []byte("version: 99\n"+v1), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(future); !errors.Is(err, ErrLoad) {
+		t.Errorf("Expected a load error for a newer config version, got %v", err)
+	}
+}
+
+func TestMigrationKeepsFieldsAndReportsChanges(t *testing.T) {
+	v1 := `employment:
+  creditable_service:
+    total_years: 25
+tsp:
+  withdrawal_source: "proportional"
+`
+	doc, notes, err := migrateConfig("plan.yaml", []byte(v1))
+	if err != nil {
+		t.Fatalf("migrateConfig failed: %v", err)
+	}
+
+	if len(notes) != 1 {
+		t.Fatalf("Expected one migration note, got %v", notes)
+	}
+	if strings.Contains(notes[0], "withdrawal_source") || !strings.Contains(notes[0], "replacement_ratio_basis") {
+		t.Errorf("Expected the note to list only the default that was added, got %q", notes[0])
+	}
+
+	root := documentRoot(doc)
+	totalYears := mappingValue(mappingValue(mappingValue(root, "employment"), "creditable_service"), "total_years")
+	if totalYears == nil || totalYears.Value != "25" || totalYears.Line != 3 {
+		t.Errorf("Expected total_years kept on line 3, got %+v", totalYears)
+	}
+	if source := mappingValue(mappingValue(root, "tsp"), "withdrawal_source"); source.Value != "proportional" {
+		t.Errorf("Expected the explicit withdrawal_source kept, got %q", source.Value)
+	}
+	if first := root.Content[0].Value; first != "employment" {
+		t.Errorf("Expected key order kept, got %q first", first)
+	}
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	data, err := yaml.Marshal(generateBasicTemplate())
+	if err != nil {
+		t.Fatalf("Failed to marshal template: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "typo.yaml")
+	typo := strings.Replace(string(data), "\nretirement:", "\nretirment:", 1)
+	if err := os.WriteFile(filename, []byte(typo), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err = LoadConfig(filename)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Expected a validation error for a misspelled key, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `unknown field "retirment"`) {
+		t.Errorf("Expected the error to name the misspelled key, got %v", err)
+	}
+
+	// A migrated version 1 file reports the line of the typo as written
+	v1 := `# Version 1 plan
+
+personal:
+  name: "Jane Doe"
+  birth_date: 1967-03-15T00:00:00Z
+
+employment:
+  hire_dat: 1999-01-15T00:00:00Z
+`
+	if err := os.WriteFile(filename, []byte(v1), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(filename); err == nil || !strings.Contains(err.Error(), `line 8: unknown field "hire_dat"`) {
+		t.Errorf("Expected the typo reported on line 8, got %v", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(filename); err != nil {
+		t.Errorf("Expected the template to load, got %v", err)
+	}
+}
+
+func TestPIADerivedFromEarningsHistory(t *testing.T) {
+	// $60,000 a year in 2025 wage terms for 35 years gives an AIME of $5,000
+	cfg := generateBasicTemplate()
+	cfg.SocialSecurity.EarningsHistory = map[int]float64{}
+	for year := 1991; year < 2026; year++ {
+		cfg.SocialSecurity.EarningsHistory[year] = 60000 / math.Pow(1.035, float64(2025-year))
+	}
+	if err := fillCalculatedFields(cfg); err != nil {
+		t.Fatalf("fillCalculatedFields failed: %v", err)
+	}
+
+	expected := math.Floor((0.90*1226+0.32*(5000-1226))*10) / 10
+	if math.Abs(cfg.SocialSecurity.EstimatedPIA-expected) > 0.1 {
+		t.Errorf("Expected estimated_pia of $%.2f derived from the earnings history, got $%.2f", expected, cfg.SocialSecurity.EstimatedPIA)
+	}
+}
+
+func TestMRAComparedInMonths(t *testing.T) {
+	// Born 1967: MRA is 56 and 6 months
+	cfg := generateBasicTemplate()
+	cfg.Employment.CreditableService.TotalYears = 30
+
+	// Two months short of the MRA is not MRA+30 eligible
+	cfg.Retirement.TargetRetirementDate = time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC) // 56 and 4 months
+	if err := validateFERSEligibility(cfg); err == nil {
+		t.Error("Expected a retiree two months short of the MRA to be ineligible")
+	}
+
+	// Reaching the MRA partway through age 56 is eligible
+	cfg.Retirement.TargetRetirementDate = time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC) // 56 and 6 months
+	if err := validateFERSEligibility(cfg); err != nil {
+		t.Errorf("Expected a retiree at the MRA to be eligible: %v", err)
+	}
+}
+
+func TestSpecialProvisionEligibleAt50With20(t *testing.T) {
+	// Born 1967: age 52 with 22 years is below the MRA and short of every regular path
+	cfg := generateBasicTemplate()
+	cfg.Employment.CreditableService.TotalYears = 22
+	cfg.Retirement.TargetRetirementDate = time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	if err := validateFERSEligibility(cfg); err == nil {
+		t.Error("Expected a regular FERS employee at 52 with 22 years to be ineligible")
+	}
+
+	cfg.Personal.SpecialProvision = true
+	if err := validateFERSEligibility(cfg); err != nil {
+		t.Errorf("Expected a special provision employee at 52 with 22 years to be eligible: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is the config schema version written by this release
+// Files without a version field are treated as version 1.
+const CurrentConfigVersion = 2
+
+// configMigration upgrades a parsed config document from one version to the next
+// migrate returns a description of each change it made.
+type configMigration struct {
+	from    int
+	migrate func(doc *yaml.Node) []string
+}
+
+// configMigrations lists the upgrade steps in version order
+var configMigrations = []configMigration{
+	{
+		from: 1,
+		migrate: func(doc *yaml.Node) []string {
+			var changes []string
+			if setDefault(doc, "traditional", "tsp", "withdrawal_source") {
+				changes = append(changes, `set tsp.withdrawal_source to its default "traditional"`)
+			}
+			if setDefault(doc, "net_high3", "retirement", "replacement_ratio_basis") {
+				changes = append(changes, `set retirement.replacement_ratio_basis to its default "net_high3"`)
+			}
+			return changes
+		},
+	},
+}
+
+// migrateConfig upgrades older config YAML to the current version
+// It works on the parsed node tree so key order and line numbers are kept, and returns
+// one note per migration step that ran; the file on disk is never rewritten.
+func migrateConfig(filename string, data []byte) (*yaml.Node, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		return &doc, nil, nil
+	}
+
+	version := 1
+	if v := mappingValue(root, "version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid config version %q", v.Line, v.Value)
+		}
+		version = n
+	}
+	if version > CurrentConfigVersion {
+		return nil, nil, fmt.Errorf("config version %d is newer than the supported version %d", version, CurrentConfigVersion)
+	}
+	if version == CurrentConfigVersion {
+		return &doc, nil, nil
+	}
+
+	var notes []string
+	for _, m := range configMigrations {
+		if m.from < version {
+			continue
+		}
+		summary := "no changes needed"
+		if changes := m.migrate(&doc); len(changes) > 0 {
+			summary = strings.Join(changes, "; ")
+		}
+		notes = append(notes, fmt.Sprintf("migrated %s from config version %d to %d: %s", filename, m.from, m.from+1, summary))
+	}
+	setField(root, strconv.Itoa(CurrentConfigVersion), "version")
+
+	return &doc, notes, nil
+}
+
+// documentRoot returns the top-level mapping of a parsed document, or nil for an empty one
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingValue returns the value node stored under key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setField stores value under key in a mapping node, replacing any existing value
+func setField(mapping *yaml.Node, value, key string) {
+	if existing := mappingValue(mapping, key); existing != nil {
+		existing.Kind, existing.Tag, existing.Value = yaml.ScalarNode, "", value
+		return
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+}
+
+// section returns the nested mapping at path, creating missing mappings along the way
+// It returns nil when a key on the path holds something other than a mapping.
+func section(doc *yaml.Node, path ...string) *yaml.Node {
+	current := documentRoot(doc)
+	for _, key := range path {
+		if current == nil {
+			return nil
+		}
+		next := mappingValue(current, key)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			current.Content = append(current.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, next)
+		} else if next.Kind != yaml.MappingNode {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// setDefault sets the field at path to value unless it is already present, reporting
+// whether it changed the document
+func setDefault(doc *yaml.Node, value string, path ...string) bool {
+	parent := section(doc, path[:len(path)-1]...)
+	if parent == nil || mappingValue(parent, path[len(path)-1]) != nil {
+		return false
+	}
+	setField(parent, value, path[len(path)-1])
+	return true
+}
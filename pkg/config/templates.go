@@ -8,10 +8,12 @@ import (
 // generateBasicTemplate creates a basic FERS employee template
 func generateBasicTemplate() *models.Config {
 	return &models.Config{
+		Version: CurrentConfigVersion,
 		Personal: models.PersonalInfo{
 			Name:             "John Doe",
 			BirthDate:        time.Date(1967, 3, 15, 0, 0, 0, 0, time.UTC),
 			RetirementSystem: "FERS",
+			MaritalStatus:    "married",
 		},
 		Employment: models.EmploymentInfo{
 			HireDate:      time.Date(1999, 1, 15, 0, 0, 0, 0, time.UTC),
@@ -33,7 +35,7 @@ func generateBasicTemplate() *models.Config {
 			RothBalance:        100000,
 			WithdrawalStrategy: "percentage",
 			WithdrawalRate:     0.04,
-			GrowthRate:         0.07,
+			GrowthRate:         models.Rate(0.07),
 		},
 		SocialSecurity: models.SocialSecurityInfo{
 			EstimatedPIA: 2800,
@@ -82,12 +84,10 @@ func generateAdvancedTemplate() *models.Config {
 	}
 
 	// EarlyRetirement is optional; set to nil if not applicable
-	var earlyRetirement *models.EarlyRetirementInfo = nil
-	// Uncomment below to include early retirement options
-	// earlyRetirement := &models.EarlyRetirementInfo{
-	// 	Type:           "MRA+10",
-	// 	PostponedStart: false,
-	// }
+	earlyRetirement := &models.EarlyRetirementInfo{
+		Type:           "MRA+10",
+		PostponedStart: false,
+	}
 
 	spouseBenefit := &models.SpouseBenefit{
 		EstimatedPIA: 2200,
@@ -95,10 +95,12 @@ func generateAdvancedTemplate() *models.Config {
 	}
 
 	return &models.Config{
+		Version: CurrentConfigVersion,
 		Personal: models.PersonalInfo{
 			Name:             "Jane Smith",
 			BirthDate:        time.Date(1965, 7, 22, 0, 0, 0, 0, time.UTC),
 			RetirementSystem: "FERS",
+			MaritalStatus:    "married",
 		},
 		Employment: models.EmploymentInfo{
 			HireDate:      time.Date(1995, 6, 1, 0, 0, 0, 0, time.UTC),
@@ -111,17 +113,17 @@ func generateAdvancedTemplate() *models.Config {
 			},
 		},
 		Retirement: models.RetirementInfo{
-			TargetRetirementDate: time.Date(2021, 7, 22, 0, 0, 0, 0, time.UTC), // Age 56 (early retirement)
+			TargetRetirementDate: time.Date(2027, 7, 22, 0, 0, 0, 0, time.UTC), // Age 62
 			SurvivorBenefit: "partial",
 			EarlyRetirement: earlyRetirement, // Optional; set to nil if not needed
 		},
 		TSP: models.TSPInfo{
 			TraditionalBalance: 550000,
 			RothBalance:        200000,
-			WithdrawalStrategy: "fixed_amount", // options: fixed_amount, percentage, life_expectancy, lump_sum
+			WithdrawalStrategy: "fixed_amount", // options: fixed_amount, percentage, life_expectancy, lump_sum, bequest, income_only, custom_schedule
 			WithdrawalAmount:   30000,           // set if strategy is fixed_amount, else 0
 			WithdrawalRate:     0,               // set if strategy is percentage, else 0
-			GrowthRate:         0.08,
+			GrowthRate:         models.Rate(0.08),
 		},
 		SocialSecurity: models.SocialSecurityInfo{
 			EstimatedPIA: 3200,
@@ -145,6 +147,9 @@ func generateAdvancedTemplate() *models.Config {
 			SSTaxExempt:      false,
 			FilingStatus:     "mfj",
 		},
+		Assumptions: models.AssumptionsInfo{
+			Profile: "base", // options: optimistic, base, pessimistic; explicit rates take precedence
+		},
 		Output: models.OutputOptions{
 			Format:     "csv",
 			Verbose:    true,
@@ -157,10 +162,12 @@ func generateAdvancedTemplate() *models.Config {
 // generateCSRSTemplate creates a CSRS employee template
 func generateCSRSTemplate() *models.Config {
 	return &models.Config{
+		Version: CurrentConfigVersion,
 		Personal: models.PersonalInfo{
 			Name:             "Robert Johnson",
 			BirthDate:        time.Date(1958, 11, 3, 0, 0, 0, 0, time.UTC),
 			RetirementSystem: "CSRS",
+			MaritalStatus:    "married",
 		},
 		Employment: models.EmploymentInfo{
 			HireDate:      time.Date(1982, 9, 15, 0, 0, 0, 0, time.UTC),
@@ -176,7 +183,7 @@ func generateCSRSTemplate() *models.Config {
 			},
 		},
 		Retirement: models.RetirementInfo{
-			TargetRetirementDate: time.Date(2024, 11, 3, 0, 0, 0, 0, time.UTC), // Age 66
+			TargetRetirementDate: time.Date(2027, 11, 3, 0, 0, 0, 0, time.UTC), // Age 69
 			SurvivorBenefit: "full",
 			EarlyRetirement: nil,
 		},
@@ -184,7 +191,7 @@ func generateCSRSTemplate() *models.Config {
 			TraditionalBalance: 250000, // CSRS employees typically have less TSP
 			RothBalance:        50000,
 			WithdrawalStrategy: "life_expectancy",
-			GrowthRate:         0.06,
+			GrowthRate:         models.Rate(0.06),
 		},
 		SocialSecurity: models.SocialSecurityInfo{
 			EstimatedPIA: 1800, // Typically lower for CSRS due to limited SS-covered employment
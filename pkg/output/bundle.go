@@ -0,0 +1,59 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+// BundleVersion is the scenario bundle format version written by NewBundle
+const BundleVersion = "1"
+
+// NewBundle packages a configuration and its results into a versioned bundle
+func NewBundle(config *models.Config, results *models.RetirementResults) (*models.ScenarioBundle, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return &models.ScenarioBundle{
+		BundleVersion: BundleVersion,
+		CreatedAt:     time.Now(),
+		Config:        *config,
+		ConfigYAML:    string(data),
+		Results:       *results,
+	}, nil
+}
+
+// LoadBundle reads a scenario bundle previously written by OutputBundle
+func LoadBundle(filename string) (*models.ScenarioBundle, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle file: %w", err)
+	}
+
+	var bundle models.ScenarioBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle JSON: %w", err)
+	}
+
+	if bundle.BundleVersion != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version: %q", bundle.BundleVersion)
+	}
+
+	if err := yaml.Unmarshal([]byte(bundle.ConfigYAML), &bundle.Config); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled config: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+// OutputBundle writes a scenario bundle as JSON regardless of the configured format
+func (o *Outputter) OutputBundle(bundle *models.ScenarioBundle) error {
+	return o.outputJSON(bundle)
+}
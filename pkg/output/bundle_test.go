@@ -0,0 +1,125 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rgehrsitz/ferex_cli/internal/models"
+	"rgehrsitz/ferex_cli/pkg/calc"
+)
+
+// createTestConfig creates a basic test configuration
+func createTestConfig() *models.Config {
+	return &models.Config{
+		Personal: models.PersonalInfo{
+			Name:             "Test User",
+			BirthDate:        time.Date(1967, 3, 15, 0, 0, 0, 0, time.UTC),
+			RetirementSystem: "FERS",
+		},
+		Employment: models.EmploymentInfo{
+			HireDate:    time.Date(1999, 1, 15, 0, 0, 0, 0, time.UTC),
+			High3Salary: 82000,
+			CreditableService: models.CreditableService{
+				TotalYears: 25,
+			},
+		},
+		Retirement: models.RetirementInfo{
+			TargetRetirementDate: time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC),
+			SurvivorBenefit:      "full",
+		},
+		TSP: models.TSPInfo{
+			TraditionalBalance: 400000,
+			RothBalance:        100000,
+			WithdrawalStrategy: "life_expectancy",
+			GrowthRate:         models.Rate(0.07),
+		},
+		SocialSecurity: models.SocialSecurityInfo{
+			EstimatedPIA: 2800,
+			ClaimingAge:  67,
+		},
+	}
+}
+
+// renderToString renders results with the given outputter into a temp file and returns the text
+func renderToString(t *testing.T, format string, verbose bool, results *models.RetirementResults) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rendered.txt")
+
+	if err := NewOutputter(format, path, verbose, false).OutputResults(results); err != nil {
+		t.Fatalf("OutputResults failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read rendered output: %v", err)
+	}
+	return string(data)
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	cfg := createTestConfig()
+	results, err := calc.NewCalculator(cfg).Calculate()
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	cfg.SocialSecurity.SuppliedPIA = 2500
+	created, err := NewBundle(cfg, results)
+	if err != nil {
+		t.Fatalf("NewBundle failed: %v", err)
+	}
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if err := NewOutputter("json", bundlePath, false, false).OutputBundle(created); err != nil {
+		t.Fatalf("OutputBundle failed: %v", err)
+	}
+
+	// The config uses the config file's key names and leaves out loader-only fields
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if !strings.Contains(string(data), "retirement_system: FERS") {
+		t.Error("Expected the bundled config to use the config file's snake_case keys")
+	}
+	if strings.Contains(string(data), "RetirementSystem") || strings.Contains(string(data), "SuppliedPIA") {
+		t.Error("Expected no Go field names in the bundled config")
+	}
+
+	bundle, err := LoadBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("LoadBundle failed: %v", err)
+	}
+
+	if bundle.BundleVersion != BundleVersion {
+		t.Errorf("Expected bundle version %s, got %s", BundleVersion, bundle.BundleVersion)
+	}
+	if bundle.Config.Personal.Name != cfg.Personal.Name {
+		t.Errorf("Expected bundled config name %s, got %s", cfg.Personal.Name, bundle.Config.Personal.Name)
+	}
+	if !bundle.Config.Personal.BirthDate.Equal(cfg.Personal.BirthDate) || *bundle.Config.TSP.GrowthRate != *cfg.TSP.GrowthRate {
+		t.Error("Expected the bundled config to round-trip dates and rates")
+	}
+	if bundle.Config.SocialSecurity.SuppliedPIA != 0 {
+		t.Errorf("Expected SuppliedPIA to stay out of the bundle, got %.2f", bundle.Config.SocialSecurity.SuppliedPIA)
+	}
+
+	original := renderToString(t, "table", true, results)
+	rendered := renderToString(t, "table", true, &bundle.Results)
+	if original != rendered {
+		t.Errorf("Re-rendered table differs from original:\n--- original ---\n%s\n--- rendered ---\n%s", original, rendered)
+	}
+}
+
+func TestLoadBundleRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, []byte(`{"bundle_version": "99"}`), 0644); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	if _, err := LoadBundle(path); err == nil {
+		t.Error("Expected error for unsupported bundle version")
+	}
+}
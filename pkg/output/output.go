@@ -1,11 +1,14 @@
 package output
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 	"rgehrsitz/ferex_cli/internal/models"
@@ -17,8 +20,19 @@ type Outputter struct {
 	outputFile string
 	verbose    bool
 	monthly    bool
+	breakEven  bool
+	stride     int
+	dateFormat string
+	csvComments bool
+	sideBySide bool
+	oneline    bool
+	ascii      bool
+	timeline   bool
 }
 
+// defaultDateFormat renders metadata dates as ISO-8601 calendar dates
+const defaultDateFormat = "2006-01-02"
+
 // NewOutputter creates a new outputter
 func NewOutputter(format, outputFile string, verbose, monthly bool) *Outputter {
 	return &Outputter{
@@ -26,14 +40,74 @@ func NewOutputter(format, outputFile string, verbose, monthly bool) *Outputter {
 		outputFile: outputFile,
 		verbose:    verbose,
 		monthly:    monthly,
+		dateFormat: defaultDateFormat,
+	}
+}
+
+// WithBreakEvenAge enables the survivor election break-even section in table output
+func (o *Outputter) WithBreakEvenAge(show bool) *Outputter {
+	o.breakEven = show
+	return o
+}
+
+// WithStride shows every Nth projection row in table output; totals still use every year
+func (o *Outputter) WithStride(stride int) *Outputter {
+	o.stride = stride
+	return o
+}
+
+// WithDateFormat sets the Go time layout for dates in table output; empty keeps the default
+func (o *Outputter) WithDateFormat(layout string) *Outputter {
+	if layout != "" {
+		o.dateFormat = layout
 	}
+	return o
+}
+
+// WithCSVComments prefixes CSV output with '#' comment lines recording the inputs and assumptions
+func (o *Outputter) WithCSVComments(show bool) *Outputter {
+	o.csvComments = show
+	return o
+}
+
+// WithSideBySide shows income sources in monthly and annual columns in table output;
+// the monthly setting picks which column comes first
+func (o *Outputter) WithSideBySide(show bool) *Outputter {
+	o.sideBySide = show
+	return o
+}
+
+// WithTimeline lists the age each income source starts and stops in the table summary
+func (o *Outputter) WithTimeline(show bool) *Outputter {
+	o.timeline = show
+	return o
+}
+
+// WithOneline replaces the results output with a single compact summary line for
+// prompts, status bars, and scripts
+func (o *Outputter) WithOneline(show bool) *Outputter {
+	o.oneline = show
+	return o
+}
+
+// WithASCII draws the table's TSP balance sparkline with ASCII characters for terminals
+// without Unicode block characters
+func (o *Outputter) WithASCII(ascii bool) *Outputter {
+	o.ascii = ascii
+	return o
 }
 
 // OutputResults outputs retirement calculation results
 func (o *Outputter) OutputResults(results *models.RetirementResults) error {
+	if o.oneline {
+		return o.writeOutput(formatOneline(results) + "\n")
+	}
+
 	switch o.format {
 	case "json":
 		return o.outputJSON(results)
+	case "ndjson":
+		return o.outputNDJSON(results)
 	case "csv":
 		return o.outputCSV(results)
 	case "yaml":
@@ -76,6 +150,240 @@ func (o *Outputter) OutputComparison(comparison *models.ComparisonResults) error
 	}
 }
 
+// OutputPensionSolution outputs the result of a target pension solve
+func (o *Outputter) OutputPensionSolution(solution models.PensionTargetSolution) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(solution)
+	case "yaml":
+		return o.outputYAML(solution)
+	default:
+		return o.writeOutput(o.formatPensionSolution(solution))
+	}
+}
+
+// formatPensionSolution formats a target pension solve as text
+func (o *Outputter) formatPensionSolution(solution models.PensionTargetSolution) string {
+	output := "Target Pension Solver\n"
+	output += "=====================\n\n"
+	
+	output += fmt.Sprintf("Target Annual Pension:     $%.2f\n", solution.TargetPension)
+	output += fmt.Sprintf("Current Annual Pension:    $%.2f (unreduced)\n", solution.CurrentPension)
+	if solution.Multiplier > 0 {
+		output += fmt.Sprintf("Multiplier (held fixed):   %.1f%%\n", solution.Multiplier*100)
+	}
+	
+	output += "\nOption 1 - Raise the High-3:\n"
+	output += fmt.Sprintf("  Current High-3:          $%.2f\n", solution.CurrentHigh3)
+	output += fmt.Sprintf("  Required High-3:         $%.2f (%+.1f%%)\n", solution.RequiredHigh3, solution.RequiredRaisePct)
+	
+	output += "\nOption 2 - Work Longer:\n"
+	output += fmt.Sprintf("  Current Service:         %.2f years\n", solution.CurrentService)
+	output += fmt.Sprintf("  Additional Service:      %.2f years\n", solution.AdditionalYears)
+	
+	return output
+}
+
+// OutputEligibility outputs retirement eligibility milestones
+func (o *Outputter) OutputEligibility(report models.EligibilityReport) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(report)
+	case "yaml":
+		return o.outputYAML(report)
+	default:
+		return o.writeOutput(o.formatEligibility(report))
+	}
+}
+
+// OutputPlanCheck outputs the result of a plan health check
+func (o *Outputter) OutputPlanCheck(check models.PlanCheck) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(check)
+	case "yaml":
+		return o.outputYAML(check)
+	default:
+		return o.writeOutput(o.formatPlanCheck(check))
+	}
+}
+
+// OutputSimulation outputs a Monte Carlo simulation summary
+func (o *Outputter) OutputSimulation(summary models.SimulationSummary) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(summary)
+	case "yaml":
+		return o.outputYAML(summary)
+	default:
+		return o.writeOutput(o.formatSimulation(summary))
+	}
+}
+
+// formatSimulation formats a Monte Carlo simulation summary as text
+func (o *Outputter) formatSimulation(summary models.SimulationSummary) string {
+	output := fmt.Sprintf("Trials:                    %d\n", summary.Trials)
+	output += fmt.Sprintf("TSP Lasted to End:         %d of %d (%.1f%%)\n",
+		summary.SurvivedTrials, summary.Trials, summary.SuccessRate*100)
+	output += "\nEnding TSP Balance:\n"
+	output += fmt.Sprintf("  10th percentile:         $%.2f\n", summary.EndingBalanceP10)
+	output += fmt.Sprintf("  25th percentile:         $%.2f\n", summary.EndingBalanceP25)
+	output += fmt.Sprintf("  Median:                  $%.2f\n", summary.EndingBalanceMedian)
+	output += fmt.Sprintf("  75th percentile:         $%.2f\n", summary.EndingBalanceP75)
+	output += fmt.Sprintf("  90th percentile:         $%.2f\n", summary.EndingBalanceP90)
+	return output
+}
+
+// OutputStressTest outputs a stress test of a plan
+func (o *Outputter) OutputStressTest(result models.StressTestResult) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(result)
+	case "yaml":
+		return o.outputYAML(result)
+	default:
+		return o.writeOutput(o.formatStressTest(result))
+	}
+}
+
+// formatStressTest formats a stress test as text
+func (o *Outputter) formatStressTest(result models.StressTestResult) string {
+	output := "Stress Conditions:\n"
+	for _, condition := range result.Conditions {
+		output += fmt.Sprintf("  - %s\n", condition)
+	}
+	output += "\n" + formatStressCase("Base Case:", result.Base)
+	output += formatStressCase("Stress Case:", result.Stress)
+	return output
+}
+
+// OutputBreakEven outputs a break-even analysis of two plans
+func (o *Outputter) OutputBreakEven(analysis models.BreakEvenAnalysis) error {
+	switch o.format {
+	case "json":
+		return o.outputJSON(analysis)
+	case "yaml":
+		return o.outputYAML(analysis)
+	default:
+		return o.writeOutput(o.formatBreakEven(analysis))
+	}
+}
+
+// formatBreakEven formats a break-even analysis as a two-column cumulative table with the
+// crossover year marked
+func (o *Outputter) formatBreakEven(analysis models.BreakEvenAnalysis) string {
+	output := "Cumulative Net Income Break-Even\n"
+	output += "================================\n\n"
+	output += fmt.Sprintf("%-6s %-5s %-20s %s\n", "Year", "Age", analysis.Labels[0], analysis.Labels[1])
+	output += strings.Repeat("-", 54) + "\n"
+	for _, row := range analysis.Rows {
+		line := fmt.Sprintf("%-6d %-5d $%-19.0f $%-19.0f", row.Year, row.Age, row.Cumulative[0], row.Cumulative[1])
+		if row.Year == analysis.CrossoverYear {
+			line += " <- crossover"
+		}
+		output += strings.TrimRight(line, " ") + "\n"
+	}
+
+	output += "\n"
+	switch {
+	case analysis.Leader < 0:
+		output += "The two plans provide the same cumulative net income every year.\n"
+	case analysis.CrossoverYear == 0:
+		output += fmt.Sprintf("%s stays ahead through the end of the projection; there is no crossover.\n",
+			analysis.Labels[analysis.Leader])
+	default:
+		output += fmt.Sprintf("%s overtakes %s in %d, at age %d.\n", analysis.Labels[1-analysis.Leader],
+			analysis.Labels[analysis.Leader], analysis.CrossoverYear, analysis.CrossoverAge)
+	}
+	return output
+}
+
+// formatIncomeComposition lists each source's share of gross income, omitting sources with none
+func formatIncomeComposition(mix models.IncomeComposition) string {
+	sources := []struct {
+		name  string
+		share float64
+	}{
+		{"Pension", mix.Pension},
+		{"Social Security", mix.SocialSecurity},
+		{"Supplement", mix.Supplement},
+		{"TSP", mix.TSP},
+		{"Other", mix.Other},
+	}
+	
+	var parts []string
+	for _, source := range sources {
+		if source.share > 0 {
+			parts = append(parts, fmt.Sprintf("%s %.1f%%", source.name, source.share*100))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatStressCase formats one stress test run as a labelled line
+func formatStressCase(label string, stressCase models.StressCase) string {
+	output := fmt.Sprintf("%-27sPASS (meets required spending every year)\n", label)
+	if !stressCase.MeetsSpending {
+		output = fmt.Sprintf("%-27sFAIL (%s short starting age %d, $%.2f total)\n", label,
+			pluralize(stressCase.ShortfallYears, "year"), stressCase.FirstShortfallAge, stressCase.TotalShortfall)
+	}
+	if stressCase.TSPDepletionAge > 0 {
+		output += fmt.Sprintf("%-27sTSP depleted at age %d\n", "", stressCase.TSPDepletionAge)
+	}
+	return output
+}
+
+// formatPlanCheck formats a plan health check as text
+func (o *Outputter) formatPlanCheck(check models.PlanCheck) string {
+	output := fmt.Sprintf("Status:                    %s\n", check.Status)
+	eligible := "yes"
+	if !check.Eligible {
+		eligible = "no"
+	}
+	output += fmt.Sprintf("Eligible:                  %s (retiring at age %d)\n", eligible, check.RetirementAge)
+	if check.PensionReductionPct > 0 {
+		output += fmt.Sprintf("Pension Reduction:         %.1f%%\n", check.PensionReductionPct)
+	}
+	
+	if len(check.Warnings) > 0 {
+		output += "\nWarnings:\n"
+		for _, warning := range check.Warnings {
+			output += fmt.Sprintf("  - %s\n", warning)
+		}
+	}
+	return output
+}
+
+// formatEligibility formats retirement eligibility milestones as text
+func (o *Outputter) formatEligibility(report models.EligibilityReport) string {
+	output := "Retirement Eligibility Milestones\n"
+	output += "=================================\n\n"
+	
+	output += fmt.Sprintf("Retirement System:         %s\n", report.RetirementSystem)
+	output += fmt.Sprintf("Birth Date:                %s\n", report.BirthDate.Format("2006-01-02"))
+	output += fmt.Sprintf("Hire Date:                 %s\n\n", report.HireDate.Format("2006-01-02"))
+	
+	output += fmt.Sprintf("%-42s %-12s %-10s %-8s %s\n", "Milestone", "Date", "Age", "Service", "Type")
+	output += "----------------------------------------------------------------------------------------------------\n"
+	for _, m := range report.Milestones {
+		output += fmt.Sprintf("%-42s %-12s %-10s %-8.1f %s\n",
+			m.Name,
+			m.Date.Format("2006-01-02"),
+			fmt.Sprintf("%dy %dm", m.AgeYears, m.AgeMonths),
+			m.ServiceYears,
+			strings.ReplaceAll(m.Type, "_", " "))
+		if !m.SeparationDate.IsZero() {
+			output += fmt.Sprintf("  separate on or after %s; annuity begins %s\n",
+				m.SeparationDate.Format("2006-01-02"), m.Date.Format("2006-01-02"))
+		}
+	}
+	
+	return output
+}
+
 // outputJSON outputs results as JSON
 func (o *Outputter) outputJSON(data interface{}) error {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
@@ -86,6 +394,23 @@ func (o *Outputter) outputJSON(data interface{}) error {
 	return o.writeOutput(string(jsonData))
 }
 
+// outputNDJSON outputs results as newline-delimited JSON: the summary object on the first
+// line, then one object per projection year, for streaming into jq and log pipelines
+func (o *Outputter) outputNDJSON(results *models.RetirementResults) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	if err := encoder.Encode(results.Summary); err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	for _, projection := range results.AnnualProjections {
+		if err := encoder.Encode(projection); err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+	}
+
+	return o.writeOutput(buf.String())
+}
+
 // outputYAML outputs results as YAML
 func (o *Outputter) outputYAML(data interface{}) error {
 	yamlData, err := yaml.Marshal(data)
@@ -107,20 +432,31 @@ func (o *Outputter) outputCSV(results *models.RetirementResults) error {
 		}
 		defer file.Close()
 
+		if o.csvComments {
+			if _, err := file.WriteString(formatCSVComments(results.Metadata)); err != nil {
+				return fmt.Errorf("failed to write CSV comments: %w", err)
+			}
+		}
+
 		writer := csv.NewWriter(file)
 		defer writer.Flush()
 
 		return o.writeCSVData(writer, results)
 	}
+	
+	if o.csvComments {
+		output = formatCSVComments(results.Metadata)
+	}
 
 	// Output to stdout (convert to string format)
 	headers := []string{
 		"Year", "Age", "Pension Income", "FERS Supplement", "Social Security", 
 		"TSP Withdrawal", "Gross Income", "Federal Tax", "State Tax", 
-		"Total Deductions", "Net Income", "TSP Balance",
+		"Total Deductions", "Net Income", "TSP Balance", "COLA Rate",
+		"Spending", "Surplus",
 	}
 	
-	output = fmt.Sprintf("%s\n", joinStrings(headers, ","))
+	output += fmt.Sprintf("%s\n", joinStrings(headers, ","))
 	
 	for _, proj := range results.AnnualProjections {
 		row := []string{
@@ -136,6 +472,9 @@ func (o *Outputter) outputCSV(results *models.RetirementResults) error {
 			fmt.Sprintf("%.2f", proj.TotalDeductions),
 			fmt.Sprintf("%.2f", proj.NetIncome),
 			fmt.Sprintf("%.2f", proj.TSPEndBalance),
+			fmt.Sprintf("%.4f", proj.COLARate),
+			fmt.Sprintf("%.2f", proj.RequiredSpending+proj.OneTimeExpenses),
+			fmt.Sprintf("%.2f", proj.Surplus),
 		}
 		output += fmt.Sprintf("%s\n", joinStrings(row, ","))
 	}
@@ -143,13 +482,36 @@ func (o *Outputter) outputCSV(results *models.RetirementResults) error {
 	return o.writeOutput(output)
 }
 
+// formatCSVComments formats the inputs and assumptions behind the results as '#' comment lines
+func formatCSVComments(metadata models.CalculationMetadata) string {
+	inputs := metadata.Inputs
+	assumptions := metadata.Assumptions
+	
+	var output string
+	if inputs.Name != "" {
+		output += fmt.Sprintf("# Name: %s\n", inputs.Name)
+	}
+	output += fmt.Sprintf("# Retirement System: %s\n", inputs.RetirementSystem)
+	output += fmt.Sprintf("# Retirement Age: %d\n", inputs.RetirementAge)
+	output += fmt.Sprintf("# High-3 Salary: %.2f\n", inputs.High3Salary)
+	if assumptions.Profile != "" {
+		output += fmt.Sprintf("# Assumption Profile: %s\n", assumptions.Profile)
+	}
+	output += fmt.Sprintf("# Inflation Rate: %.4f\n", assumptions.InflationRate)
+	output += fmt.Sprintf("# TSP Growth Rate: %.4f\n", assumptions.TSPGrowthRate)
+	output += fmt.Sprintf("# COLA Rate: %.4f\n", assumptions.FERSCOLARate)
+	output += fmt.Sprintf("# Life Expectancy: %d\n", assumptions.LifeExpectancy)
+	return output
+}
+
 // writeCSVData writes CSV data using csv.Writer
 func (o *Outputter) writeCSVData(writer *csv.Writer, results *models.RetirementResults) error {
 	// Write headers
 	headers := []string{
 		"Year", "Age", "Pension Income", "FERS Supplement", "Social Security", 
 		"TSP Withdrawal", "Gross Income", "Federal Tax", "State Tax", 
-		"Total Deductions", "Net Income", "TSP Balance",
+		"Total Deductions", "Net Income", "TSP Balance", "COLA Rate",
+		"Spending", "Surplus",
 	}
 	
 	if err := writer.Write(headers); err != nil {
@@ -171,6 +533,9 @@ func (o *Outputter) writeCSVData(writer *csv.Writer, results *models.RetirementR
 			fmt.Sprintf("%.2f", proj.TotalDeductions),
 			fmt.Sprintf("%.2f", proj.NetIncome),
 			fmt.Sprintf("%.2f", proj.TSPEndBalance),
+			fmt.Sprintf("%.4f", proj.COLARate),
+			fmt.Sprintf("%.2f", proj.RequiredSpending+proj.OneTimeExpenses),
+			fmt.Sprintf("%.2f", proj.Surplus),
 		}
 		
 		if err := writer.Write(row); err != nil {
@@ -185,20 +550,41 @@ func (o *Outputter) writeCSVData(writer *csv.Writer, results *models.RetirementR
 func (o *Outputter) outputTable(results *models.RetirementResults) error {
 	output := o.formatSummaryTable(results.Summary)
 	
+	if sampled := sampleProjections(results.AnnualProjections, o.stride); len(sampled) > 1 {
+		output += fmt.Sprintf("\nTSP Balance Trend:         %s (ages %d-%d)\n",
+			o.formatSparkline(sampled), sampled[0].Age, sampled[len(sampled)-1].Age)
+	}
+	
 	if o.verbose {
 		output += "\n\nDetailed Annual Projections:\n"
 		output += o.formatProjectionTable(results.AnnualProjections)
 	}
+	
+	output += o.formatMetadata(results.Metadata)
 
 	return o.writeOutput(output)
 }
 
+// formatMetadata formats the calculation metadata footer
+func (o *Outputter) formatMetadata(metadata models.CalculationMetadata) string {
+	if metadata.CalculationDate.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("\nCalculated: %s\n", metadata.CalculationDate.Format(o.dateFormat))
+}
+
 // formatSummaryTable formats the retirement summary as a table
 func (o *Outputter) formatSummaryTable(summary models.RetirementSummary) string {
 	output := "Retirement Planning Summary\n"
 	output += "===========================\n\n"
 	
-	if o.monthly {
+	if summary.CreditableService > 0 {
+		output += fmt.Sprintf("Creditable Service:        %s\n", formatServiceDuration(summary.CreditableService))
+	}
+	
+	if o.sideBySide {
+		output += o.formatIncomeColumns(summary)
+	} else if o.monthly {
 		output += fmt.Sprintf("Monthly Pension:           $%.2f\n", summary.MonthlyPension)
 		output += fmt.Sprintf("Monthly Social Security:   $%.2f (starting age %d)\n", 
 			summary.MonthlySocialSecurity, summary.SocialSecurityStartAge)
@@ -212,69 +598,290 @@ func (o *Outputter) formatSummaryTable(summary models.RetirementSummary) string
 		output += fmt.Sprintf("Annual Pension:            $%.2f\n", summary.AnnualPension)
 	}
 	
+	if summary.PhasedEndAge > 0 {
+		output += fmt.Sprintf("Phased Annuity:            $%.2f/year (until age %d)\n", summary.PhasedAnnuity, summary.PhasedEndAge)
+		output += fmt.Sprintf("Composite Annuity:         $%.2f/year (from age %d)\n", summary.CompositeAnnuity, summary.PhasedEndAge)
+	}
+	
 	if summary.PensionReductionPct > 0 {
 		output += fmt.Sprintf("Pension Reduction:         %.1f%%\n", summary.PensionReductionPct)
 	}
 	
+	if summary.ExcessContributionRefund > 0 {
+		output += fmt.Sprintf("Contribution Refund:       $%.2f (untaxed CSRS excess refund at retirement)\n", summary.ExcessContributionRefund)
+	}
+	
+	if summary.AnnualLeavePayout > 0 {
+		output += fmt.Sprintf("Annual Leave Payout:       $%.2f (taxable lump sum at retirement)\n", summary.AnnualLeavePayout)
+	}
+	
+	if summary.TSPRothConversions > 0 {
+		output += fmt.Sprintf("Roth Conversions (TSP):    $%.2f (in-plan, taxable)\n", summary.TSPRothConversions)
+	}
+	if summary.IRARothConversions > 0 {
+		output += fmt.Sprintf("Roth Conversions (IRA):    $%.2f (taxable)\n", summary.IRARothConversions)
+	}
+	
 	if summary.SurvivorBenefitCost > 0 {
-		output += fmt.Sprintf("Survivor Benefit Cost:     $%.2f/year\n", summary.SurvivorBenefitCost*12)
+		output += fmt.Sprintf("Survivor Benefit Cost:     $%.2f/year\n", summary.SurvivorBenefitCost)
 	}
 	
-	if summary.FERSSupplement > 0 {
-		output += fmt.Sprintf("FERS Supplement:           $%.2f/month (until age %d)\n", 
-			summary.FERSSupplement, summary.SupplementEndAge)
+	if o.breakEven && summary.SurvivorBreakEvenAge > 0 {
+		output += fmt.Sprintf("Survivor Annuity:          $%.2f/year\n", summary.SurvivorAnnuity)
+		output += fmt.Sprintf("Survivor Break-Even Age:   %.1f (election pays off if death occurs before this age)\n", summary.SurvivorBreakEvenAge)
+	}
+	
+	if !o.sideBySide {
+		if summary.FERSSupplement > 0 {
+			output += fmt.Sprintf("FERS Supplement:           $%.2f/month (until age %d)\n", 
+				summary.FERSSupplement, summary.SupplementEndAge)
+		}
+		
+		output += fmt.Sprintf("Social Security:           $%.2f/month (starting age %d)\n", 
+			summary.MonthlySocialSecurity, summary.SocialSecurityStartAge)
 	}
 	
-	output += fmt.Sprintf("Social Security:           $%.2f/month (starting age %d)\n", 
-		summary.MonthlySocialSecurity, summary.SocialSecurityStartAge)
+	if summary.IncomeGapYears > 0 {
+		output += fmt.Sprintf("Income Gap:                ages %d-%d (%s with no supplement or Social Security)\n",
+			summary.IncomeGapStartAge, summary.IncomeGapStartAge+summary.IncomeGapYears-1,
+			pluralize(summary.IncomeGapYears, "year"))
+		if summary.IncomeGapDip > 0 {
+			output += fmt.Sprintf("Income Dip:                $%.2f/year entering the gap\n", summary.IncomeGapDip)
+		}
+	}
 	
 	output += fmt.Sprintf("TSP Starting Balance:      $%.2f\n", summary.TSPStartingBalance)
 	
+	if summary.SustainableWithdrawalRate > 0 {
+		output += fmt.Sprintf("Sustainable Withdrawal:    %.1f%% of the starting balance, rising with inflation\n",
+			summary.SustainableWithdrawalRate*100)
+	}
+	
+	if summary.TargetNetIncome > 0 {
+		output += fmt.Sprintf("Target Net Income:         $%.2f (first year)\n", summary.TargetNetIncome)
+		output += fmt.Sprintf("Required TSP Withdrawal:   $%.2f/year gross\n", summary.SolvedTSPWithdrawal)
+	}
+	
 	if summary.TSPProjectedDepletion > 0 {
 		output += fmt.Sprintf("TSP Depletion Age:         %d\n", summary.TSPProjectedDepletion)
 	}
 	
+	if summary.ShortfallYears > 0 {
+		output += fmt.Sprintf("Spending Shortfall:        %s starting age %d, $%.2f total\n",
+			pluralize(summary.ShortfallYears, "year"), summary.FirstShortfallAge, summary.TotalShortfall)
+	}
+	
+	if summary.FirstTaxCliffAge > 0 {
+		output += fmt.Sprintf("\nFirst Tax Cliff:           age %d (%d), into the %.0f%% bracket\n",
+			summary.FirstTaxCliffAge, summary.FirstTaxCliffYear, summary.TaxCliffBracketRate*100)
+	}
+	
 	output += fmt.Sprintf("\nFirst Year Income:         $%.2f\n", summary.FirstYearIncome)
 	output += fmt.Sprintf("Lifetime Income:           $%.2f\n", summary.LifetimeIncome)
-	output += fmt.Sprintf("Replacement Ratio:         %.1f%%\n", summary.ReplacementRatio*100)
+	if summary.LifetimeIncomeNPV > 0 {
+		output += fmt.Sprintf("Lifetime Income (PV):      $%.2f (discounted to retirement)\n", summary.LifetimeIncomeNPV)
+	}
+	if summary.ExpectedLifetimeIncome > 0 {
+		output += fmt.Sprintf("Expected Lifetime Income:  $%.2f (mortality-weighted)\n", summary.ExpectedLifetimeIncome)
+	}
+	output += fmt.Sprintf("Replacement Ratio:         %.1f%%", summary.ReplacementRatio*100)
+	switch summary.ReplacementRatioBasis {
+	case "gross_final_salary":
+		output += " (gross income / final salary)\n"
+	case "net_high3":
+		output += " (net income / high-3)\n"
+	default:
+		output += "\n"
+	}
+	if summary.NetReplacementRatio > 0 {
+		output += fmt.Sprintf("Net Replacement Ratio:     %.1f%% (vs. $%.2f take-home while working)\n",
+			summary.NetReplacementRatio*100, summary.WorkingNetIncome)
+	}
+	output += fmt.Sprintf("Income Mix (first year):   %s\n", formatIncomeComposition(summary.FirstYearIncomeMix))
+	output += fmt.Sprintf("Income Mix (lifetime):     %s\n", formatIncomeComposition(summary.LifetimeIncomeMix))
+	
+	if summary.SupplementGapYears > 0 {
+		output += fmt.Sprintf("Supplement Gap:            %s after the FERS supplement ends, $%.2f of income lost\n",
+			pluralize(summary.SupplementGapYears, "year"), summary.SupplementGapLoss)
+	}
+	
+	if o.timeline && len(summary.Timeline) > 0 {
+		output += "\nIncome Timeline:\n"
+		for _, event := range summary.Timeline {
+			output += fmt.Sprintf("  Age %d (%d)  %s\n", event.Age, event.Year, event.Event)
+		}
+	}
+	
+	if len(summary.Suggestions) > 0 {
+		output += "\nSuggestions:\n"
+		for _, suggestion := range summary.Suggestions {
+			output += fmt.Sprintf("  - %s\n", suggestion)
+		}
+	}
+	
+	if len(summary.DataQuality) > 0 {
+		output += "\nData Quality:\n"
+		for _, note := range summary.DataQuality {
+			output += fmt.Sprintf("  - %s\n", note)
+		}
+	}
 	
 	return output
 }
 
+// formatIncomeColumns formats each income source with monthly and annual amounts side by side
+// The monthly setting puts the monthly column first; otherwise the annual column leads.
+func (o *Outputter) formatIncomeColumns(summary models.RetirementSummary) string {
+	primary, secondary := "Annual", "Monthly"
+	if o.monthly {
+		primary, secondary = secondary, primary
+	}
+	
+	output := fmt.Sprintf("%-26s %14s %14s\n", "Income Source", primary, secondary)
+	row := func(label string, monthlyAmount float64, note string) {
+		first, second := monthlyAmount*12, monthlyAmount
+		if o.monthly {
+			first, second = second, first
+		}
+		output += fmt.Sprintf("%-26s %14s %14s%s\n", label,
+			fmt.Sprintf("$%.2f", first), fmt.Sprintf("$%.2f", second), note)
+	}
+	
+	row("Pension", summary.MonthlyPension, "")
+	if summary.FERSSupplement > 0 {
+		row("FERS Supplement", summary.FERSSupplement, fmt.Sprintf("  (until age %d)", summary.SupplementEndAge))
+	}
+	row("Social Security", summary.MonthlySocialSecurity, fmt.Sprintf("  (starting age %d)", summary.SocialSecurityStartAge))
+	row("First Year Net Income", summary.FirstYearIncome/12, "")
+	return output + "\n"
+}
+
 // formatProjectionTable formats annual projections as a table
 func (o *Outputter) formatProjectionTable(projections []models.AnnualProjection) string {
-	output := fmt.Sprintf("%-6s %-4s %-12s %-12s %-12s %-12s %-12s %-12s\n",
-		"Year", "Age", "Pension", "SS", "TSP Withdraw", "Gross", "Net", "TSP Balance")
-	output += fmt.Sprintf("%s\n", "------------------------------------------------------------------------------------")
+	spending := hasSpending(projections)
 	
-	for i, proj := range projections {
+	output := fmt.Sprintf("%-6s %-4s %-12s %-12s %-12s %-12s %-12s %-12s %-8s %-8s %-8s",
+		"Year", "Age", "Pension", "SS", "TSP Withdraw", "Gross", "Net", "TSP Balance", "Eff Tax", "Marg Tax", "COLA")
+	divider := "---------------------------------------------------------------------------------------------------------------"
+	if spending {
+		output += fmt.Sprintf(" %-12s %-12s", "Spending", "Surplus")
+		divider += "--------------------------"
+	}
+	output += fmt.Sprintf("\n%s\n", divider)
+	
+	for i, proj := range sampleProjections(projections, o.stride) {
 		if i > 20 && !o.verbose { // Limit output unless verbose
 			output += fmt.Sprintf("... (use --verbose for complete projection)\n")
 			break
 		}
 		
-		output += fmt.Sprintf("%-6d %-4d $%-11.0f $%-11.0f $%-11.0f $%-11.0f $%-11.0f $%-11.0f\n",
+		cliff := ""
+		if proj.BracketCrossing {
+			cliff = " *"
+		}
+		
+		output += fmt.Sprintf("%-6d %-4d $%-11.0f $%-11.0f $%-11.0f $%-11.0f $%-11.0f $%-11.0f %-8s %-8s %-8s",
 			proj.Year, proj.Age, proj.PensionIncome, proj.SocialSecurityIncome,
-			proj.TSPWithdrawal, proj.GrossIncome, proj.NetIncome, proj.TSPEndBalance)
+			proj.TSPWithdrawal, proj.GrossIncome, proj.NetIncome, proj.TSPEndBalance,
+			fmt.Sprintf("%.1f%%", proj.EffectiveTaxRate*100), fmt.Sprintf("%.1f%%", proj.MarginalTaxRate*100),
+			fmt.Sprintf("%.2f%%", proj.COLARate*100))
+		if spending {
+			output += fmt.Sprintf(" $%-11.0f $%-11.0f", proj.RequiredSpending+proj.OneTimeExpenses, proj.Surplus)
+		}
+		output += cliff + "\n"
+	}
+	
+	if hasBracketCrossing(projections) {
+		output += "\n* Taxable income crosses into a higher federal bracket than the prior year\n"
 	}
 	
 	return output
 }
 
+// Sparkline levels from an empty to the largest balance
+var (
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+	sparkASCII  = []rune("_.-:=+*#")
+)
+
+// formatSparkline draws each year's ending TSP balance as one of 8 levels scaled from
+// zero to the largest balance, one character per year
+func (o *Outputter) formatSparkline(projections []models.AnnualProjection) string {
+	levels := sparkBlocks
+	if o.ascii {
+		levels = sparkASCII
+	}
+
+	var peak float64
+	for _, p := range projections {
+		peak = math.Max(peak, p.TSPEndBalance)
+	}
+
+	line := make([]rune, len(projections))
+	for i, p := range projections {
+		level := 0
+		if peak > 0 {
+			level = int(math.Round(math.Max(p.TSPEndBalance, 0) / peak * float64(len(levels)-1)))
+		}
+		line[i] = levels[level]
+	}
+	return string(line)
+}
+
+// sampleProjections returns every stride-th projection, starting with the first year
+func sampleProjections(projections []models.AnnualProjection, stride int) []models.AnnualProjection {
+	if stride <= 1 {
+		return projections
+	}
+	
+	var sampled []models.AnnualProjection
+	for i := 0; i < len(projections); i += stride {
+		sampled = append(sampled, projections[i])
+	}
+	return sampled
+}
+
+// hasSpending reports whether any year has spending to compare against income
+func hasSpending(projections []models.AnnualProjection) bool {
+	for _, p := range projections {
+		if p.RequiredSpending+p.OneTimeExpenses > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBracketCrossing reports whether any projection year crosses into a higher tax bracket
+func hasBracketCrossing(projections []models.AnnualProjection) bool {
+	for _, proj := range projections {
+		if proj.BracketCrossing {
+			return true
+		}
+	}
+	return false
+}
+
 // outputComparisonCSV outputs comparison results as CSV
 func (o *Outputter) outputComparisonCSV(comparison *models.ComparisonResults) error {
-	output := "Scenario,Retirement Age,Monthly Pension,Annual Pension,First Year Income,Lifetime Income,Replacement Ratio,TSP Depletion Age\n"
+	output := "Scenario,Retirement Age,Monthly Pension,Annual Pension,First Year Income,Lifetime Income,Replacement Ratio,TSP Depletion Age,Lifetime Income Delta,TSP Depletion Delta\n"
 	
 	for i, scenario := range comparison.Scenarios {
-		row := fmt.Sprintf("Scenario %d,%d,%.2f,%.2f,%.2f,%.2f,%.2f,%d\n",
+		delta := comparisonDelta(comparison, i)
+		depletionDelta := ""
+		if delta.TSPDepletionDelta != nil {
+			depletionDelta = fmt.Sprintf("%d", *delta.TSPDepletionDelta)
+		}
+		row := fmt.Sprintf("Scenario %d,%d,%.2f,%.2f,%.2f,%.2f,%.2f,%d,%.2f,%s\n",
 			i+1, 
-			scenario.AnnualProjections[0].Age, // Retirement age
+			scenario.Metadata.Inputs.RetirementAge,
 			scenario.Summary.MonthlyPension,
 			scenario.Summary.AnnualPension,
 			scenario.Summary.FirstYearIncome,
 			scenario.Summary.LifetimeIncome,
 			scenario.Summary.ReplacementRatio*100,
-			scenario.Summary.TSPProjectedDepletion)
+			scenario.Summary.TSPProjectedDepletion,
+			delta.LifetimeIncome,
+			depletionDelta)
 		output += row
 	}
 	
@@ -286,21 +893,33 @@ func (o *Outputter) outputComparisonTable(comparison *models.ComparisonResults)
 	output := "Retirement Age Comparison\n"
 	output += "=========================\n\n"
 	
-	output += fmt.Sprintf("%-10s %-15s %-15s %-15s %-15s %-15s %-15s\n",
-		"Age", "Monthly Pension", "Annual Pension", "First Yr Income", "Lifetime Income", "Replace Ratio", "TSP Depletion")
-	output += "--------------------------------------------------------------------------------------------------------\n"
+	output += fmt.Sprintf("%-10s %-15s %-15s %-15s %-15s %-15s %-15s %-15s %-15s\n",
+		"Age", "Monthly Pension", "Annual Pension", "First Yr Income", "Lifetime Income", "Replace Ratio", "TSP Depletion",
+		"Δ Lifetime", "Δ Depletion")
+	output += "----------------------------------------------------------------------------------------------------------------------------------------\n"
 	
-	for _, scenario := range comparison.Scenarios {
-		retirementAge := scenario.AnnualProjections[0].Age
+	for i, scenario := range comparison.Scenarios {
+		retirementAge := scenario.Metadata.Inputs.RetirementAge
+		delta := comparisonDelta(comparison, i)
+		depletionDelta := "-"
+		if delta.TSPDepletionDelta != nil {
+			depletionDelta = fmt.Sprintf("%+d", *delta.TSPDepletionDelta)
+		}
 		
-		output += fmt.Sprintf("%-10d $%-14.0f $%-14.0f $%-14.0f $%-14.0f %-14.1f%% %-14d\n",
+		output += fmt.Sprintf("%-10d $%-14.0f $%-14.0f $%-14.0f $%-14.0f %-14.1f%% %-15d %-15s %-15s\n",
 			retirementAge,
 			scenario.Summary.MonthlyPension,
 			scenario.Summary.AnnualPension,
 			scenario.Summary.FirstYearIncome,
 			scenario.Summary.LifetimeIncome,
 			scenario.Summary.ReplacementRatio*100,
-			scenario.Summary.TSPProjectedDepletion)
+			scenario.Summary.TSPProjectedDepletion,
+			formatSignedDollars(delta.LifetimeIncome),
+			depletionDelta)
+	}
+	if len(comparison.Scenarios) > 0 {
+		output += fmt.Sprintf("\nDeltas are relative to the age %d baseline.\n",
+			comparison.Scenarios[comparison.Baseline].Metadata.Inputs.RetirementAge)
 	}
 	
 	output += "\nComparison Metrics:\n"
@@ -311,6 +930,54 @@ func (o *Outputter) outputComparisonTable(comparison *models.ComparisonResults)
 	return o.writeOutput(output)
 }
 
+// formatOneline summarizes the results in one line of space-separated key=value fields,
+// e.g. "FERS age62 pension=$22,550/yr SS@67=$2,800/mo net1=$58,200 depletes=never"
+func formatOneline(results *models.RetirementResults) string {
+	summary := results.Summary
+	var netIncome float64
+	if len(results.AnnualProjections) > 0 {
+		netIncome = results.AnnualProjections[0].NetIncome
+	}
+	depletes := "never"
+	if summary.TSPProjectedDepletion > 0 {
+		depletes = fmt.Sprintf("%d", summary.TSPProjectedDepletion)
+	}
+
+	return fmt.Sprintf("%s age%d pension=%s/yr SS@%d=%s/mo net1=%s depletes=%s",
+		results.Metadata.Inputs.RetirementSystem, results.Metadata.Inputs.RetirementAge,
+		formatWholeDollars(summary.AnnualPension), summary.SocialSecurityStartAge,
+		formatWholeDollars(summary.MonthlySocialSecurity), formatWholeDollars(netIncome), depletes)
+}
+
+// formatWholeDollars formats an amount as whole dollars with thousands separators
+func formatWholeDollars(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := fmt.Sprintf("%.0f", amount)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + "$" + digits
+}
+
+// comparisonDelta returns a scenario's delta from the baseline, or none if deltas were not computed
+func comparisonDelta(comparison *models.ComparisonResults, i int) models.ScenarioDelta {
+	if i < len(comparison.Deltas) {
+		return comparison.Deltas[i]
+	}
+	return models.ScenarioDelta{}
+}
+
+// formatSignedDollars formats a dollar difference with an explicit sign
+func formatSignedDollars(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.0f", -amount)
+	}
+	return fmt.Sprintf("+$%.0f", amount)
+}
+
 // writeOutput writes output to file or stdout
 func (o *Outputter) writeOutput(content string) error {
 	if o.outputFile != "" {
@@ -321,6 +988,30 @@ func (o *Outputter) writeOutput(content string) error {
 	return nil
 }
 
+// formatServiceDuration renders fractional service years as years, months, and days
+// using OPM's convention of 30-day months and 12-month years, omitting zero months or days.
+func formatServiceDuration(years float64) string {
+	totalDays := int(math.Round(years * 360))
+	parts := []string{pluralize(totalDays/360, "year")}
+	
+	if months := totalDays % 360 / 30; months > 0 {
+		parts = append(parts, pluralize(months, "month"))
+	}
+	if days := totalDays % 30; days > 0 {
+		parts = append(parts, pluralize(days, "day"))
+	}
+	
+	return joinStrings(parts, ", ")
+}
+
+// pluralize formats a count with a singular or plural unit
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
 // joinStrings joins a slice of strings with a separator
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
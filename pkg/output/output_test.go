@@ -0,0 +1,277 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"rgehrsitz/ferex_cli/internal/models"
+)
+
+func TestFormatServiceDuration(t *testing.T) {
+	testCases := []struct {
+		years    float64
+		expected string
+	}{
+		{25.5, "25 years, 6 months"},
+		{30, "30 years"},
+		{1 + 1.0/12 + 1.0/360, "1 year, 1 month, 1 day"},
+		{20 + 2.0/12 + 12.0/360, "20 years, 2 months, 12 days"},
+	}
+
+	for _, tc := range testCases {
+		if got := formatServiceDuration(tc.years); got != tc.expected {
+			t.Errorf("formatServiceDuration(%.4f) = %q, expected %q", tc.years, got, tc.expected)
+		}
+	}
+}
+
+func TestProjectionTableStride(t *testing.T) {
+	projections := make([]models.AnnualProjection, 34)
+	for i := range projections {
+		projections[i] = models.AnnualProjection{Year: 2029 + i, Age: 62 + i}
+	}
+
+	table := NewOutputter("table", "", true, false).WithStride(5).formatProjectionTable(projections)
+
+	rows := 0
+	for _, line := range strings.Split(table, "\n") {
+		if strings.HasPrefix(line, "20") {
+			rows++
+		}
+	}
+	if rows != 7 {
+		t.Errorf("Expected 7 rows with stride 5 over 34 years, got %d:\n%s", rows, table)
+	}
+}
+
+func TestMetadataDateFormat(t *testing.T) {
+	metadata := models.CalculationMetadata{CalculationDate: time.Date(2025, time.March, 7, 14, 30, 0, 0, time.UTC)}
+
+	if got := NewOutputter("table", "", false, false).formatMetadata(metadata); !strings.Contains(got, "Calculated: 2025-03-07") {
+		t.Errorf("Expected ISO-8601 date by default, got %q", got)
+	}
+
+	custom := NewOutputter("table", "", false, false).WithDateFormat("Jan 2, 2006").formatMetadata(metadata)
+	if !strings.Contains(custom, "Calculated: Mar 7, 2025") {
+		t.Errorf("Expected custom date format, got %q", custom)
+	}
+}
+
+func TestSideBySideMonthlyPrimary(t *testing.T) {
+	summary := models.RetirementSummary{
+		MonthlyPension:         2000,
+		AnnualPension:          24000,
+		MonthlySocialSecurity:  2800,
+		SocialSecurityStartAge: 67,
+	}
+
+	lines := strings.Split(NewOutputter("table", "", false, true).WithSideBySide(true).formatSummaryTable(summary), "\n")
+	fields := func(prefix string) []string {
+		for _, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				return strings.Fields(strings.TrimPrefix(line, prefix))
+			}
+		}
+		t.Fatalf("Expected a %q row", prefix)
+		return nil
+	}
+
+	if header := fields("Income Source"); len(header) != 2 || header[0] != "Monthly" || header[1] != "Annual" {
+		t.Errorf("Expected Monthly then Annual columns, got %v", header)
+	}
+	if pension := fields("Pension"); pension[0] != "$2000.00" || pension[1] != "$24000.00" {
+		t.Errorf("Expected monthly pension first and annual second, got %v", pension)
+	}
+	if ss := fields("Social Security"); ss[0] != "$2800.00" || ss[1] != "$33600.00" {
+		t.Errorf("Expected monthly Social Security first and annual second, got %v", ss)
+	}
+
+	lines = strings.Split(NewOutputter("table", "", false, false).WithSideBySide(true).formatSummaryTable(summary), "\n")
+	if header := fields("Income Source"); header[0] != "Annual" {
+		t.Errorf("Expected the annual column first without monthly display, got %v", header)
+	}
+}
+
+func TestCSVCommentsRecordInputs(t *testing.T) {
+	results := &models.RetirementResults{
+		AnnualProjections: []models.AnnualProjection{{Year: 2029, Age: 62}},
+		Metadata: models.CalculationMetadata{
+			Inputs: models.CalculationInputs{
+				Name:             "John Doe",
+				RetirementSystem: "FERS",
+				RetirementAge:    62,
+				High3Salary:      82000,
+			},
+			Assumptions: models.CalculationAssumptions{LifeExpectancy: 95},
+		},
+	}
+
+	file := filepath.Join(t.TempDir(), "results.csv")
+	if err := NewOutputter("csv", file, false, false).WithCSVComments(true).OutputResults(results); err != nil {
+		t.Fatalf("OutputResults failed: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	for _, want := range []string{"# Retirement System: FERS\n", "# High-3 Salary: 82000.00\n", "# Life Expectancy: 95\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected header comment %q, got:\n%s", want, data)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse commented CSV: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "Year" {
+		t.Errorf("Expected header and one data row after comments, got %v", records)
+	}
+
+
+	plain := filepath.Join(t.TempDir(), "plain.csv")
+	if err := NewOutputter("csv", plain, false, false).OutputResults(results); err != nil {
+		t.Fatalf("OutputResults failed: %v", err)
+	}
+	if data, _ := os.ReadFile(plain); strings.HasPrefix(string(data), "#") {
+		t.Error("Expected no header comments unless enabled")
+	}
+}
+
+func TestCSVIncludesCOLARate(t *testing.T) {
+	results := &models.RetirementResults{
+		AnnualProjections: []models.AnnualProjection{
+			{Year: 2029, Age: 62, COLARate: 0},
+			{Year: 2030, Age: 63, COLARate: 0.015},
+		},
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := NewOutputter("csv", "", false, false).writeCSVData(writer, results); err != nil {
+		t.Fatalf("writeCSVData failed: %v", err)
+	}
+	writer.Flush()
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	column := -1
+	for i, header := range records[0] {
+		if header == "COLA Rate" {
+			column = i
+		}
+	}
+	if column < 0 {
+		t.Fatalf("Expected a COLA Rate column, got headers %v", records[0])
+	}
+
+	for i, proj := range results.AnnualProjections {
+		got, err := strconv.ParseFloat(records[i+1][column], 64)
+		if err != nil || got != proj.COLARate {
+			t.Errorf("Expected COLA rate %.4f at age %d, got %q", proj.COLARate, proj.Age, records[i+1][column])
+		}
+	}
+}
+
+func TestOnelineSummary(t *testing.T) {
+	results := &models.RetirementResults{
+		Summary: models.RetirementSummary{
+			AnnualPension:          22550,
+			MonthlySocialSecurity:  2800,
+			SocialSecurityStartAge: 67,
+		},
+		AnnualProjections: []models.AnnualProjection{{Year: 2029, Age: 62, NetIncome: 58200}},
+		Metadata: models.CalculationMetadata{
+			Inputs: models.CalculationInputs{RetirementSystem: "FERS", RetirementAge: 62},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "oneline.txt")
+	if err := NewOutputter("table", path, false, false).WithOneline(true).OutputResults(results); err != nil {
+		t.Fatalf("OutputResults failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	line := string(data)
+
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Errorf("Expected a single line, got %q", line)
+	}
+	for _, want := range []string{"FERS", "SS@67=$2,800/mo", "net1=$58,200", "pension=$22,550/yr", "depletes=never"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in the oneline output, got %q", want, line)
+		}
+	}
+}
+
+func TestNDJSONOnePerLine(t *testing.T) {
+	results := &models.RetirementResults{
+		Summary: models.RetirementSummary{AnnualPension: 22550},
+		AnnualProjections: []models.AnnualProjection{
+			{Year: 2029, Age: 62, NetIncome: 58200},
+			{Year: 2030, Age: 63, NetIncome: 59100},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	if err := NewOutputter("ndjson", path, false, false).OutputResults(results); err != nil {
+		t.Fatalf("OutputResults failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 1+len(results.AnnualProjections) {
+		t.Fatalf("Expected a summary line and one line per projection year, got %d lines", len(lines))
+	}
+
+	var summary models.RetirementSummary
+	if err := json.Unmarshal([]byte(lines[0]), &summary); err != nil || summary.AnnualPension != 22550 {
+		t.Errorf("Expected the summary on the first line, got %q (%v)", lines[0], err)
+	}
+	for i, line := range lines[1:] {
+		var projection models.AnnualProjection
+		if err := json.Unmarshal([]byte(line), &projection); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %v", i+2, err)
+		}
+		if projection != results.AnnualProjections[i] {
+			t.Errorf("Expected projection %+v on line %d, got %+v", results.AnnualProjections[i], i+2, projection)
+		}
+	}
+}
+
+func TestSparklineShowsDepletingTrend(t *testing.T) {
+	var projections []models.AnnualProjection
+	for i, balance := range []float64{500000, 520000, 540000, 540000, 400000, 200000, 50000, 0} {
+		projections = append(projections, models.AnnualProjection{Age: 62 + i, TSPEndBalance: balance})
+	}
+
+	sparkline := []rune(NewOutputter("table", "", false, false).formatSparkline(projections))
+	if len(sparkline) != len(projections) {
+		t.Fatalf("Expected one character per year, got %q", string(sparkline))
+	}
+	if sparkline[2] != '█' || sparkline[len(sparkline)-1] != '▁' {
+		t.Errorf("Expected a full block at the peak and the lowest block once depleted, got %q", string(sparkline))
+	}
+	for i := 4; i < len(sparkline); i++ {
+		if sparkline[i] > sparkline[i-1] {
+			t.Errorf("Expected the sparkline to fall while the balance depletes, got %q", string(sparkline))
+		}
+	}
+
+	sampled := sampleProjections(projections, 3)
+	ascii := NewOutputter("table", "", false, false).WithASCII(true).formatSparkline(sampled)
+	if ascii != "*#." {
+		t.Errorf("Expected one ASCII character per sampled year, got %q", ascii)
+	}
+}